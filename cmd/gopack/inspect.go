@@ -0,0 +1,145 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/spf13/cobra"
+)
+
+func newInspectCommand() *cobra.Command {
+	opts := defaultCLIOptions()
+	cmd := &cobra.Command{
+		Use:   "inspect <reference|oci:path>",
+		Short: "Print the manifests, config and layers of an image or OCI archive",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateLogOptions(opts); err != nil {
+				return err
+			}
+			if err := validateFormat(opts.format); err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			logger := newLogger(cmd.ErrOrStderr(), opts)
+			options, err := buildRegistryOptions(opts)
+			if err != nil {
+				return err
+			}
+			options = append(options, gopack.WithLogger(logger))
+
+			res, err := gopack.Inspect(ctx, args[0], options...)
+			if err != nil {
+				return reportError(cmd, opts, logger, withAuthHint(err))
+			}
+			return writeInspection(cmd.OutOrStdout(), opts.format, res)
+		},
+	}
+	cmd.Flags().StringVar(&opts.format, "format", opts.format, "output format (supported: text, json)")
+	addLogFlags(cmd, opts)
+	addRegistryFlags(cmd, opts)
+	return cmd
+}
+
+func writeInspection(w io.Writer, format string, res *gopack.Inspection) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reference: %s\n", res.Reference)
+	fmt.Fprintf(&b, "Digest:    %s\n", res.Digest)
+	fmt.Fprintf(&b, "MediaType: %s\n", res.MediaType)
+	for _, img := range res.Images {
+		fmt.Fprintf(&b, "\n%s\n", img.Platform)
+		fmt.Fprintf(&b, "  Digest:     %s\n", img.Digest)
+		fmt.Fprintf(&b, "  MediaType:  %s\n", img.MediaType)
+		if !img.Config.Created.IsZero() {
+			fmt.Fprintf(&b, "  Created:    %s\n", img.Config.Created.UTC().Format(time.RFC3339))
+		}
+		writeList(&b, "Entrypoint", img.Config.Entrypoint)
+		writeList(&b, "Cmd", img.Config.Cmd)
+		if img.Config.User != "" {
+			fmt.Fprintf(&b, "  User:       %s\n", img.Config.User)
+		}
+		if img.Config.WorkingDir != "" {
+			fmt.Fprintf(&b, "  WorkingDir: %s\n", img.Config.WorkingDir)
+		}
+		if len(img.Config.Env) > 0 {
+			b.WriteString("  Env:\n")
+			for _, env := range img.Config.Env {
+				fmt.Fprintf(&b, "    %s\n", env)
+			}
+		}
+		if len(img.Config.Labels) > 0 {
+			b.WriteString("  Labels:\n")
+			for _, key := range slices.Sorted(maps.Keys(img.Config.Labels)) {
+				fmt.Fprintf(&b, "    %s=%s\n", key, img.Config.Labels[key])
+			}
+		}
+
+		var total int64
+		b.WriteString("  Layers:\n")
+		for _, layer := range img.Layers {
+			total += layer.Size
+			fmt.Fprintf(&b, "    %s  %s\n", layer.Digest, types.FormatSize(layer.Size))
+		}
+		fmt.Fprintf(&b, "  Size:       %s\n", types.FormatSize(total))
+
+		if len(img.History) > 0 {
+			b.WriteString("  History:\n")
+			for _, h := range img.History {
+				desc := h.CreatedBy
+				if desc == "" {
+					desc = h.Comment
+				}
+				if h.Created.IsZero() {
+					fmt.Fprintf(&b, "    %s\n", desc)
+				} else {
+					fmt.Fprintf(&b, "    %s  %s\n", h.Created.UTC().Format(time.RFC3339), desc)
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeList(b *strings.Builder, label string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	quoted, _ := json.Marshal(values)
+	fmt.Fprintf(b, "  %-11s %s\n", label+":", quoted)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+)
+
+func TestWriteInspection(t *testing.T) {
+	res := &gopack.Inspection{
+		Reference: "example.com/app:v1",
+		Digest:    "sha256:index",
+		MediaType: "application/vnd.oci.image.index.v1+json",
+		Images: []gopack.ImageInspection{{
+			Platform:  "linux/amd64",
+			Digest:    "sha256:image",
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Config: gopack.ConfigInspection{
+				Entrypoint: []string{"/app"},
+				User:       "nonroot",
+				Labels:     map[string]string{"b": "2", "a": "1"},
+			},
+			Layers: []gopack.LayerResult{
+				{Digest: "sha256:base", Size: 800},
+				{Digest: "sha256:app", Size: 1_250_000},
+			},
+			History: []gopack.HistoryInspection{{CreatedBy: "gopack"}},
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := writeInspection(&out, formatText, res); err != nil {
+		t.Fatal(err)
+	}
+	want := `Reference: example.com/app:v1
+Digest:    sha256:index
+MediaType: application/vnd.oci.image.index.v1+json
+
+linux/amd64
+  Digest:     sha256:image
+  MediaType:  application/vnd.oci.image.manifest.v1+json
+  Entrypoint: ["/app"]
+  User:       nonroot
+  Labels:
+    a=1
+    b=2
+  Layers:
+    sha256:base  800 B
+    sha256:app  1.2 MB
+  Size:       1.3 MB
+  History:
+    gopack
+`
+	if out.String() != want {
+		t.Fatalf("inspection output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestInspectRejectsUnsupportedFormat(t *testing.T) {
+	_, err := executeCommand("inspect", "example.com/app:v1", "--format", "yaml")
+	if err == nil {
+		t.Fatal("command error = nil, want unsupported format error")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+	"github.com/ryanfowler/gopack/internal/oci"
+
+	"github.com/spf13/cobra"
+)
+
+type loginOptions struct {
+	caFiles       []string
+	insecure      bool
+	password      string
+	passwordStdin bool
+	username      string
+}
+
+func newLoginCommand() *cobra.Command {
+	opts := &loginOptions{}
+	cmd := &cobra.Command{
+		Use:   "login <registry>",
+		Short: "Log in to a registry and store the credentials in the Docker config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			username, password, err := loginCredentials(opts, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			registryOptions := []oci.RegistryOption{oci.WithCAFiles(opts.caFiles)}
+			if opts.insecure {
+				registryOptions = append(registryOptions, oci.WithInsecureRegistries(args))
+			}
+			if err := oci.Login(ctx, args[0], username, password, registryOptions...); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Login succeeded")
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&opts.username, "username", "u", "", "registry username (default $"+gopack.EnvRegistryUsername+")")
+	cmd.Flags().StringVarP(&opts.password, "password", "p", "", "registry password (default $"+gopack.EnvRegistryPassword+")")
+	cmd.Flags().BoolVar(&opts.passwordStdin, "password-stdin", false, "read the password from stdin")
+	cmd.Flags().BoolVar(&opts.insecure, "insecure", false, "access the registry over plain HTTP")
+	cmd.Flags().StringSliceVar(&opts.caFiles, "registry-ca", nil, "PEM CA bundles to trust for the registry")
+	return cmd
+}
+
+func loginCredentials(opts *loginOptions, stdin io.Reader) (string, string, error) {
+	username := opts.username
+	if username == "" {
+		username = os.Getenv(gopack.EnvRegistryUsername)
+	}
+	if username == "" {
+		return "", "", errors.New("login requires --username")
+	}
+
+	password := opts.password
+	if opts.passwordStdin {
+		if password != "" {
+			return "", "", errors.New("cannot use --password with --password-stdin")
+		}
+		raw, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", "", fmt.Errorf("reading password from stdin: %w", err)
+		}
+		password = strings.TrimRight(string(raw), "\r\n")
+	}
+	if password == "" {
+		password = os.Getenv(gopack.EnvRegistryPassword)
+	}
+	if password == "" {
+		return "", "", errors.New("login requires --password or --password-stdin")
+	}
+	return username, password, nil
+}
+
+func newLogoutCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <registry>",
+		Short: "Remove stored credentials for a registry from the Docker config",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := oci.Logout(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed credentials for %s\n", args[0])
+			return nil
+		},
+	}
+}
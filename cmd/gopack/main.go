@@ -16,12 +16,17 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/ryanfowler/gopack/internal/gopack"
 	"github.com/ryanfowler/gopack/internal/oci"
@@ -32,6 +37,15 @@ import (
 
 const dockerDaemon = "docker"
 
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// errReported is returned when the error has already been reported through the
+// logger, so it should not be printed again.
+var errReported = errors.New("error reported")
+
 type commandMode int
 
 const (
@@ -42,20 +56,54 @@ const (
 )
 
 type cliOptions struct {
-	base        string
-	cgoEnabled  bool
-	compression int
-	concurrency int
-	daemon      string
-	labels      []string
-	ldflags     string
-	load        bool
-	mod         string
-	output      string
-	platforms   []string
-	repository  string
-	tags        []string
-	trimpath    bool
+	base         []string
+	buildTags    []string
+	cgoEnabled   bool
+	compiler     string
+	compression  int
+	concurrency  int
+	createRepo   bool
+	daemon       string
+	dryRun       string
+	env          []string
+	format       string
+	goWork       string
+	immutable    []string
+	immutableAll bool
+	labels       []string
+	layerComp    string
+	ldflags      []string
+	load         bool
+	logFormat    string
+	maxBinary    string
+	maxImage     string
+	metadata     string
+	mod          string
+	output       string
+	platforms    []string
+	platformTags bool
+	prebuilt     []string
+	pushJobs     int
+	quiet        bool
+	repositories []string
+	since        string
+	sizeBudget   string
+	skipExisting bool
+	tagStrategy  string
+	tagTemplates []string
+	tags         []string
+	trimpath     bool
+	upx          bool
+	verbose      bool
+	workdir      string
+
+	caFiles            []string
+	credentials        []string
+	insecureRegistries []string
+	mirrors            []string
+	registryTimeout    time.Duration
+	retries            int
+	retryBackoff       time.Duration
 }
 
 var rootCmd = newRootCmd()
@@ -74,6 +122,10 @@ func newRootCmd() *cobra.Command {
 		newPublishCommand(),
 		newBuildCommand(),
 		newLoadCommand(),
+		newPushCommand(),
+		newInspectCommand(),
+		newLoginCommand(),
+		newLogoutCommand(),
 	)
 	return cmd
 }
@@ -129,43 +181,265 @@ func newPackageCommand(mode commandMode, opts *cliOptions) *cobra.Command {
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
 
+			logger := newLogger(cmd.ErrOrStderr(), opts)
 			options, err := buildRunOptions(mode, opts, args)
 			if err != nil {
 				return err
 			}
+			options = append(options, gopack.WithLogger(logger))
+
+			if pattern, ok := packagesPattern(opts, args); ok {
+				res, err := gopack.RunPackages(ctx, pattern, options...)
+				if err != nil {
+					// Report the packages that were already published.
+					if res != nil && len(res.Results) > 0 {
+						if werr := writePackagesOutputs(cmd, opts, res); werr != nil {
+							err = errors.Join(err, werr)
+						}
+					}
+					return reportError(cmd, opts, logger, withAuthHint(err))
+				}
+				return writePackagesOutputs(cmd, opts, res)
+			}
 
-			out, err := gopack.Run(ctx, options...)
+			res, err := gopack.Run(ctx, options...)
 			if err != nil {
-				return err
+				// Report the sizes of a build that failed its size budget.
+				if res != nil {
+					if werr := writeOutputs(cmd, opts, res); werr != nil {
+						err = errors.Join(err, werr)
+					}
+				}
+				return reportError(cmd, opts, logger, withAuthHint(err))
 			}
-			fmt.Fprintln(cmd.OutOrStdout(), out)
-			return nil
+			return writeOutputs(cmd, opts, res)
 		},
 	}
 }
 
+func newLogger(w io.Writer, opts *cliOptions) types.Logger {
+	level := types.LevelInfo
+	if opts.quiet {
+		level = types.LevelError
+	} else if opts.verbose {
+		level = types.LevelDebug
+	}
+	if opts.logFormat == formatJSON {
+		return gopack.NewJSONLogger(w, level)
+	}
+	return gopack.NewTextLogger(w, level)
+}
+
+// withAuthHint adds a hint on how to provide credentials to registry
+// authentication errors.
+func withAuthHint(err error) error {
+	var authErr *oci.AuthError
+	if !errors.As(err, &authErr) {
+		return err
+	}
+	return fmt.Errorf("%w\nhint: run `gopack login %s` or pass --registry-auth", err, authErr.Registry)
+}
+
+// reportError logs err as a structured message when JSON logging is enabled,
+// instead of letting cobra print it as plain text.
+func reportError(cmd *cobra.Command, opts *cliOptions, logger types.Logger, err error) error {
+	if opts.logFormat != formatJSON {
+		return err
+	}
+	logger.Errorf("%s", err)
+	cmd.SilenceErrors = true
+	return errReported
+}
+
+// writeOutputs writes the result to the metadata file, if any, and stdout.
+func writeOutputs(cmd *cobra.Command, opts *cliOptions, res *gopack.Result) error {
+	if opts.metadata != "" {
+		if err := writeMetadataFile(opts.metadata, res); err != nil {
+			return err
+		}
+	}
+	return writeResult(cmd.OutOrStdout(), opts.format, res)
+}
+
+// packagesPattern returns the package pattern to build with RunPackages, if
+// the package is a pattern matching several packages or --since is used.
+func packagesPattern(opts *cliOptions, args []string) (string, bool) {
+	pattern := "."
+	if len(args) == 1 {
+		pattern = args[0]
+	}
+	return pattern, opts.since != "" || strings.Contains(pattern, "...")
+}
+
+// writePackagesOutputs writes the result of building several packages to the
+// metadata file, if any, and stdout.
+func writePackagesOutputs(cmd *cobra.Command, opts *cliOptions, res *gopack.PackagesResult) error {
+	if opts.metadata != "" {
+		if err := writeMetadataFile(opts.metadata, res); err != nil {
+			return err
+		}
+	}
+	w := cmd.OutOrStdout()
+	if opts.format == formatJSON {
+		return writeJSON(w, res)
+	}
+	for _, pkgRes := range res.Results {
+		if err := writeResult(w, opts.format, pkgRes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeResult(w io.Writer, format string, res *gopack.Result) error {
+	if format == formatJSON {
+		return writeJSON(w, res)
+	}
+	if res.DryRun != "" {
+		return writePlan(w, res)
+	}
+	refs := res.References
+	if len(refs) == 0 {
+		refs = []string{res.Reference}
+	}
+	for _, ref := range refs {
+		if _, err := fmt.Fprintln(w, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePlan(w io.Writer, res *gopack.Result) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dry run (%s): nothing was written\n", res.DryRun)
+	if res.Base.Reference != "" {
+		fmt.Fprintf(&b, "Base: %s@%s\n", res.Base.Reference, res.Base.Digest)
+	}
+	if res.Digest != "" {
+		fmt.Fprintf(&b, "Digest: %s (%s)\n", res.Digest, res.MediaType)
+	}
+	b.WriteString("Images:\n")
+	for _, img := range res.Images {
+		if img.BaseReference != "" {
+			fmt.Fprintf(&b, "  %s  base %s@%s", img.Platform, img.BaseReference, img.Base)
+		} else {
+			fmt.Fprintf(&b, "  %s  base %s", img.Platform, img.Base)
+		}
+		if img.Digest != "" {
+			fmt.Fprintf(&b, "  image %s", img.Digest)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Destinations:\n")
+	destinations := res.Tags
+	if res.Archive != "" {
+		destinations = []string{res.Archive}
+	}
+	for _, img := range res.Images {
+		destinations = append(slices.Clip(destinations), img.Tags...)
+	}
+	for _, dest := range destinations {
+		fmt.Fprintf(&b, "  %s\n", dest)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMetadataFile(path string, res any) error {
+	raw, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(raw, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing metadata file: %w", err)
+	}
+	return nil
+}
+
 func defaultCLIOptions() *cliOptions {
 	return &cliOptions{
-		base:        "gcr.io/distroless/static:nonroot",
+		compiler:    gopack.CompilerGo,
 		compression: -1,
-		platforms:   []string{types.DefaultPlatform.String()},
-		tags:        []string{oci.DefaultTag},
+		format:      formatText,
+		layerComp:   "gzip",
+		logFormat:   formatText,
+		sizeBudget:  gopack.SizeBudgetFail,
 		trimpath:    true,
+
+		retries:      2,
+		retryBackoff: time.Second,
 	}
 }
 
 func addCommonFlags(cmd *cobra.Command, opts *cliOptions) {
-	cmd.Flags().StringVarP(&opts.base, "base", "b", opts.base, "repository to use as the base image")
+	cmd.Flags().StringArrayVarP(&opts.base, "base", "b", opts.base, "repository to use as the base image, or <platform>=<image> for one platform (default gcr.io/distroless/static:nonroot)")
+	cmd.Flags().IntVarP(&opts.concurrency, "build-concurrency", "c", opts.concurrency, "number of concurrent builds (default GOMAXPROCS)")
+	cmd.Flags().StringArrayVar(&opts.buildTags, "build-tags", opts.buildTags, "comma-separated build tags used during compilation, or <platform>=<tags> to add tags for one platform")
 	cmd.Flags().BoolVar(&opts.cgoEnabled, "cgo", opts.cgoEnabled, "enable CGO during Go compilation")
-	cmd.Flags().IntVar(&opts.compression, "compression", opts.compression, "gzip compression level of image layers")
-	cmd.Flags().IntVarP(&opts.concurrency, "concurrency", "c", opts.concurrency, "number of concurrent builds (default GOMAXPROCS)")
+	cmd.Flags().StringVar(&opts.compiler, "compiler", opts.compiler, "compiler used to build the main package (supported: go, tinygo)")
+	cmd.Flags().IntVar(&opts.compression, "compression", opts.compression, "compression level of image layers")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", opts.concurrency, "number of concurrent builds (default GOMAXPROCS)")
+	cmd.Flags().MarkDeprecated("concurrency", "use --build-concurrency instead")
+	cmd.Flags().StringArrayVar(&opts.env, "env", opts.env, "environment variable used during compilation as KEY=VALUE, or <platform>=KEY=VALUE for one platform")
+	cmd.Flags().StringVar(&opts.goWork, "gowork", opts.goWork, "go.work file used during compilation, or off to disable workspaces (default $GOWORK)")
 	cmd.Flags().StringSliceVarP(&opts.labels, "label", "l", opts.labels, "labels to include in image")
-	cmd.Flags().StringVar(&opts.ldflags, "ldflags", opts.ldflags, "ldflags used during Go compilation")
+	cmd.Flags().StringVar(&opts.layerComp, "layer-compression", opts.layerComp, "compression of the application layer (supported: gzip, zstd, none)")
+	cmd.Flags().StringArrayVar(&opts.ldflags, "ldflags", opts.ldflags, "ldflags used during Go compilation, or <platform>=<ldflags> for one platform")
+	cmd.Flags().StringVar(&opts.maxBinary, "max-binary-size", opts.maxBinary, "budget for the compressed application layer of each image (e.g. 20MB)")
+	cmd.Flags().StringVar(&opts.maxImage, "max-image-size", opts.maxImage, "budget for the total compressed size of each image (e.g. 50MB)")
 	cmd.Flags().StringVar(&opts.mod, "mod", opts.mod, "mod flag used during Go compilation")
-	cmd.Flags().StringSliceVarP(&opts.platforms, "platform", "p", opts.platforms, "platforms to build for")
-	cmd.Flags().StringVarP(&opts.repository, "repository", "r", opts.repository, "repository to name or push image as")
-	cmd.Flags().StringSliceVarP(&opts.tags, "tag", "t", opts.tags, "tags to apply to the image")
+	cmd.Flags().StringSliceVarP(&opts.platforms, "platform", "p", opts.platforms, "platforms to build for (default linux/amd64, or those of --prebuilt)")
+	cmd.Flags().StringArrayVar(&opts.prebuilt, "prebuilt", opts.prebuilt, "package a binary built elsewhere as <platform>=<path> instead of compiling")
+	cmd.Flags().IntVar(&opts.pushJobs, "push-jobs", opts.pushJobs, "number of images uploaded concurrently as they are built (default 4)")
+	cmd.Flags().StringVar(&opts.since, "since", opts.since, "only build the main packages affected by changes since a git ref")
+	cmd.Flags().StringVar(&opts.sizeBudget, "size-budget", opts.sizeBudget, "action when a size budget is exceeded (supported: fail, warn)")
 	cmd.Flags().BoolVar(&opts.trimpath, "trimpath", opts.trimpath, "enable trimpath during Go compilation")
+	cmd.Flags().BoolVar(&opts.upx, "upx", opts.upx, "pack binaries with upx, if installed")
+	cmd.Flags().StringVar(&opts.workdir, "workdir", opts.workdir, "directory to run go and git commands in, which the package path is relative to")
+	addPushFlags(cmd, opts)
+	addLogFlags(cmd, opts)
+	addRegistryFlags(cmd, opts)
+}
+
+// addPushFlags adds the flags that control where and how an image is
+// published, shared by the build commands and push.
+func addPushFlags(cmd *cobra.Command, opts *cliOptions) {
+	cmd.Flags().BoolVar(&opts.createRepo, "create-repository", opts.createRepo, "create the repository if missing (Amazon ECR only)")
+	cmd.Flags().StringVar(&opts.dryRun, "dry-run", opts.dryRun, "print the plan without writing anything; use --dry-run=build to also compile")
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = gopack.DryRunPlan
+	cmd.Flags().StringVar(&opts.format, "format", opts.format, "result output format (supported: text, json)")
+	cmd.Flags().BoolVar(&opts.immutableAll, "immutable", opts.immutableAll, "refuse to move any existing tag")
+	cmd.Flags().StringSliceVar(&opts.immutable, "immutable-tags", opts.immutable, "refuse to move existing tags matching these patterns (e.g. 'v*')")
+	cmd.Flags().StringVar(&opts.metadata, "metadata-file", opts.metadata, "write the JSON result to this file")
+	cmd.Flags().BoolVar(&opts.platformTags, "platform-tags", opts.platformTags, "also tag each platform's image as <tag>-<os>-<arch>[-<variant>]")
+	cmd.Flags().StringSliceVarP(&opts.repositories, "repository", "r", opts.repositories, "repositories to name or push image as")
+	cmd.Flags().BoolVar(&opts.skipExisting, "skip-if-exists", opts.skipExisting, "do nothing if the image already exists in the repository")
+	cmd.Flags().StringSliceVarP(&opts.tags, "tag", "t", opts.tags, "tags to apply to the image (default latest)")
+	cmd.Flags().StringVar(&opts.tagStrategy, "tag-strategy", opts.tagStrategy, "derive tags from git (supported: semver)")
+	cmd.Flags().StringArrayVar(&opts.tagTemplates, "tag-template", opts.tagTemplates, "Go template rendered as a tag (fields: .Commit, .ShortCommit, .Branch, .Date)")
+}
+
+func addRegistryFlags(cmd *cobra.Command, opts *cliOptions) {
+	cmd.Flags().StringArrayVar(&opts.credentials, "registry-auth", opts.credentials, "credentials for a registry as <registry>=<env|token-file:path|helper:name>")
+	cmd.Flags().StringSliceVar(&opts.insecureRegistries, "insecure-registry", opts.insecureRegistries, "registries to access over plain HTTP")
+	cmd.Flags().StringSliceVar(&opts.caFiles, "registry-ca", opts.caFiles, "PEM CA bundles to trust for registries")
+	cmd.Flags().StringSliceVar(&opts.mirrors, "registry-mirror", opts.mirrors, "mirror to pull the base from (e.g. index.docker.io=mirror.example.com)")
+	cmd.Flags().DurationVar(&opts.registryTimeout, "registry-timeout", opts.registryTimeout, "time to wait for a registry response (default no timeout)")
+	cmd.Flags().IntVar(&opts.retries, "retries", opts.retries, "number of times to retry failed registry requests")
+	cmd.Flags().DurationVar(&opts.retryBackoff, "retry-backoff", opts.retryBackoff, "delay before the first registry retry")
+}
+
+func addLogFlags(cmd *cobra.Command, opts *cliOptions) {
+	cmd.Flags().StringVar(&opts.logFormat, "log-format", opts.logFormat, "log message format (supported: text, json)")
+	cmd.Flags().BoolVarP(&opts.quiet, "quiet", "q", opts.quiet, "only log errors")
+	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", opts.verbose, "enable debug logging")
 }
 
 func addLoadFlags(cmd *cobra.Command, opts *cliOptions) {
@@ -178,6 +452,13 @@ func addDaemonFlag(cmd *cobra.Command, opts *cliOptions, daemonDefault string) {
 }
 
 func validateCommandOptions(mode commandMode, opts *cliOptions) error {
+	if err := validateLogOptions(opts); err != nil {
+		return err
+	}
+	if err := validateFormat(opts.format); err != nil {
+		return err
+	}
+
 	switch mode {
 	case modeBuild:
 		if opts.output == "" {
@@ -193,6 +474,27 @@ func validateCommandOptions(mode commandMode, opts *cliOptions) error {
 	return nil
 }
 
+func validateFormat(format string) error {
+	switch format {
+	case formatText, formatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q (supported: %s, %s)", format, formatText, formatJSON)
+	}
+}
+
+func validateLogOptions(opts *cliOptions) error {
+	switch opts.logFormat {
+	case formatText, formatJSON:
+	default:
+		return fmt.Errorf("unsupported log format %q (supported: %s, %s)", opts.logFormat, formatText, formatJSON)
+	}
+	if opts.quiet && opts.verbose {
+		return errors.New("cannot use --quiet with --verbose")
+	}
+	return nil
+}
+
 func buildRunOptions(mode commandMode, opts *cliOptions, args []string) ([]gopack.RunOption, error) {
 	options := []gopack.RunOption{
 		gopack.WithCGOEnabled(opts.cgoEnabled),
@@ -202,8 +504,16 @@ func buildRunOptions(mode commandMode, opts *cliOptions, args []string) ([]gopac
 	if opts.concurrency > 0 {
 		options = append(options, gopack.WithConcurrency(opts.concurrency))
 	}
-	if opts.ldflags != "" {
-		options = append(options, gopack.WithLDFlags(opts.ldflags))
+	if opts.pushJobs > 0 {
+		options = append(options, gopack.WithPushJobs(opts.pushJobs))
+	}
+	platformOptions, err := buildPlatformOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, platformOptions...)
+	if opts.compiler != "" {
+		options = append(options, gopack.WithCompiler(opts.compiler))
 	}
 	if len(args) == 1 {
 		options = append(options, gopack.WithMainPath(args[0]))
@@ -211,12 +521,24 @@ func buildRunOptions(mode commandMode, opts *cliOptions, args []string) ([]gopac
 	if opts.mod != "" {
 		options = append(options, gopack.WithModFlag(opts.mod))
 	}
-	if opts.base != "" {
-		options = append(options, gopack.WithBase(opts.base))
+	if opts.workdir != "" {
+		options = append(options, gopack.WithWorkdir(opts.workdir))
+	}
+	if opts.goWork != "" {
+		options = append(options, gopack.WithGoWork(opts.goWork))
+	}
+	if opts.since != "" {
+		options = append(options, gopack.WithSince(opts.since))
 	}
 	if opts.compression >= 0 {
 		options = append(options, gopack.WithCompressionLevel(opts.compression))
 	}
+	if opts.layerComp != "" {
+		options = append(options, gopack.WithLayerCompression(opts.layerComp))
+	}
+	if opts.upx {
+		options = append(options, gopack.WithUPX(true))
+	}
 	if opts.daemon != "" {
 		options = append(options, gopack.WithDaemon(opts.daemon))
 	}
@@ -236,19 +558,302 @@ func buildRunOptions(mode commandMode, opts *cliOptions, args []string) ([]gopac
 	if len(opts.platforms) > 0 {
 		options = append(options, gopack.WithPlatforms(opts.platforms))
 	}
-	if opts.repository != "" {
-		options = append(options, gopack.WithRepository(opts.repository))
+	if len(opts.prebuilt) > 0 {
+		m, err := parsePrebuilt(opts.prebuilt)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, gopack.WithPrebuilt(m))
 	}
-	if len(opts.tags) > 0 {
-		options = append(options, gopack.WithTags(opts.tags))
+	if opts.maxImage != "" {
+		size, err := types.ParseSize(opts.maxImage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max image size: %w", err)
+		}
+		options = append(options, gopack.WithMaxImageSize(size))
+	}
+	if opts.maxBinary != "" {
+		size, err := types.ParseSize(opts.maxBinary)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max binary size: %w", err)
+		}
+		options = append(options, gopack.WithMaxBinarySize(size))
 	}
+	options = append(options, gopack.WithSizeBudgetMode(opts.sizeBudget))
 	if mode == modeLoad {
 		options = append(options, gopack.WithLoad(true))
 	}
 
+	options = append(options, buildPushOptions(opts)...)
+
+	registryOptions, err := buildRegistryOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, registryOptions...)
+
+	return options, nil
+}
+
+// buildPushOptions returns the options that control where and how an image
+// is published.
+func buildPushOptions(opts *cliOptions) []gopack.RunOption {
+	var options []gopack.RunOption
+	if opts.createRepo {
+		options = append(options, gopack.WithCreateRepository(true))
+	}
+	if opts.dryRun != "" {
+		options = append(options, gopack.WithDryRun(opts.dryRun))
+	}
+	immutable := opts.immutable
+	if opts.immutableAll {
+		immutable = append(slices.Clip(immutable), "*")
+	}
+	if len(immutable) > 0 {
+		options = append(options, gopack.WithImmutableTags(immutable))
+	}
+	if opts.platformTags {
+		options = append(options, gopack.WithPlatformTags(true))
+	}
+	if opts.skipExisting {
+		options = append(options, gopack.WithSkipIfExists(true))
+	}
+	if len(opts.repositories) > 0 {
+		options = append(options, gopack.WithRepositories(opts.repositories))
+	}
+	if len(opts.tags) > 0 {
+		options = append(options, gopack.WithTags(opts.tags))
+	}
+	if opts.tagStrategy != "" {
+		options = append(options, gopack.WithTagStrategy(opts.tagStrategy))
+	}
+	if len(opts.tagTemplates) > 0 {
+		options = append(options, gopack.WithTagTemplates(opts.tagTemplates))
+	}
+	return options
+}
+
+func buildRegistryOptions(opts *cliOptions) ([]gopack.RunOption, error) {
+	options := []gopack.RunOption{
+		gopack.WithRetries(opts.retries),
+		gopack.WithRetryBackoff(opts.retryBackoff),
+	}
+	if opts.registryTimeout > 0 {
+		options = append(options, gopack.WithRegistryTimeout(opts.registryTimeout))
+	}
+	if len(opts.insecureRegistries) > 0 {
+		options = append(options, gopack.WithInsecureRegistries(opts.insecureRegistries))
+	}
+	if len(opts.caFiles) > 0 {
+		options = append(options, gopack.WithCAFiles(opts.caFiles))
+	}
+	if len(opts.mirrors) > 0 {
+		m, err := parseMirrors(opts.mirrors)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, gopack.WithRegistryMirrors(m))
+	}
+	if len(opts.credentials) > 0 {
+		m, err := parseRegistryAuth(opts.credentials)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, gopack.WithCredentials(m))
+	}
 	return options, nil
 }
 
+// parseRegistryAuth parses values of the form <registry>=<source>, where the
+// source is "env", "token-file:<path>", or "helper:<name>".
+func parseRegistryAuth(values []string) (map[string]types.Credential, error) {
+	m := make(map[string]types.Credential, len(values))
+	for _, value := range values {
+		registry, source, ok := strings.Cut(value, "=")
+		if !ok || registry == "" || source == "" {
+			return nil, fmt.Errorf("invalid registry auth %q: must be <registry>=<source>", value)
+		}
+
+		var cred types.Credential
+		switch {
+		case source == "env":
+			cred.Username = os.Getenv(gopack.EnvRegistryUsername)
+			cred.Password = os.Getenv(gopack.EnvRegistryPassword)
+			if cred.Username == "" || cred.Password == "" {
+				return nil, fmt.Errorf("registry auth for %s: %s and %s must be set",
+					registry, gopack.EnvRegistryUsername, gopack.EnvRegistryPassword)
+			}
+		case strings.HasPrefix(source, "token-file:"):
+			cred.TokenFile = strings.TrimPrefix(source, "token-file:")
+		case strings.HasPrefix(source, "helper:"):
+			cred.Helper = strings.TrimPrefix(source, "helper:")
+		}
+		if cred == (types.Credential{}) {
+			return nil, fmt.Errorf("invalid registry auth source %q (supported: env, token-file:<path>, helper:<name>)", source)
+		}
+		m[registry] = cred
+	}
+	return m, nil
+}
+
+func parseMirrors(mirrors []string) (map[string]string, error) {
+	m := make(map[string]string, len(mirrors))
+	for _, mirror := range mirrors {
+		registry, target, ok := strings.Cut(mirror, "=")
+		if !ok || registry == "" || target == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q: must be <registry>=<mirror>", mirror)
+		}
+		m[registry] = target
+	}
+	return m, nil
+}
+
+// buildPlatformOptions returns the options for --base, --ldflags,
+// --build-tags and --env. Each value applies to every platform or, given as
+// <platform>=<value>, to a single platform.
+func buildPlatformOptions(opts *cliOptions) ([]gopack.RunOption, error) {
+	var options []gopack.RunOption
+	overrides := make(map[string]gopack.PlatformOptions)
+	override := func(flag, value, platform string, set func(*gopack.PlatformOptions) bool) error {
+		p := types.ParsePlatform(platform)
+		if !p.IsSupported() {
+			return fmt.Errorf("invalid --%s %q: unsupported platform %q", flag, value, platform)
+		}
+		po := overrides[p.String()]
+		if !set(&po) {
+			return fmt.Errorf("invalid --%s %q: duplicate platform %s", flag, value, p)
+		}
+		overrides[p.String()] = po
+		return nil
+	}
+
+	var base, ldflags string
+	for _, value := range opts.base {
+		platform, image, ok := splitPlatformValue(value)
+		if !ok {
+			if base != "" {
+				return nil, fmt.Errorf("invalid --base %q: the base is already %q; use <platform>=<image> for a single platform", value, base)
+			}
+			base = image
+			continue
+		}
+		err := override("base", value, platform, func(po *gopack.PlatformOptions) bool {
+			if po.Base != "" {
+				return false
+			}
+			po.Base = image
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, value := range opts.ldflags {
+		platform, flags, ok := splitPlatformValue(value)
+		if !ok {
+			if ldflags != "" {
+				return nil, fmt.Errorf("invalid --ldflags %q: the ldflags are already %q; use <platform>=<ldflags> for a single platform", value, ldflags)
+			}
+			ldflags = flags
+			continue
+		}
+		err := override("ldflags", value, platform, func(po *gopack.PlatformOptions) bool {
+			if po.LDFlags != "" {
+				return false
+			}
+			po.LDFlags = flags
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var buildTags []string
+	for _, value := range opts.buildTags {
+		platform, tags, ok := splitPlatformValue(value)
+		if !ok {
+			buildTags = append(buildTags, splitTags(tags)...)
+			continue
+		}
+		err := override("build-tags", value, platform, func(po *gopack.PlatformOptions) bool {
+			po.BuildTags = append(po.BuildTags, splitTags(tags)...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var env []string
+	for _, value := range opts.env {
+		platform, variable, ok := splitPlatformValue(value)
+		if !ok {
+			env = append(env, variable)
+			continue
+		}
+		err := override("env", value, platform, func(po *gopack.PlatformOptions) bool {
+			po.Env = append(po.Env, variable)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if base != "" {
+		options = append(options, gopack.WithBase(base))
+	}
+	if ldflags != "" {
+		options = append(options, gopack.WithLDFlags(ldflags))
+	}
+	if len(buildTags) > 0 {
+		options = append(options, gopack.WithBuildTags(buildTags))
+	}
+	if len(env) > 0 {
+		options = append(options, gopack.WithEnv(env))
+	}
+	if len(overrides) > 0 {
+		options = append(options, gopack.WithPlatformOptions(overrides))
+	}
+	return options, nil
+}
+
+// splitPlatformValue splits a flag value of the form <platform>=<value>. A
+// value is only prefixed by a platform if the text before its first "=" looks
+// like one, so that values such as "-X main.version=1.0" and "CC=clang" apply
+// to every platform.
+func splitPlatformValue(value string) (platform, v string, ok bool) {
+	before, after, found := strings.Cut(value, "=")
+	if !found || !strings.Contains(before, "/") || strings.HasPrefix(before, "-") || strings.ContainsAny(before, " \t") {
+		return "", value, false
+	}
+	return before, after, true
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for tag := range strings.SplitSeq(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parsePrebuilt(values []string) (map[string]string, error) {
+	m := make(map[string]string, len(values))
+	for _, value := range values {
+		platform, path, ok := strings.Cut(value, "=")
+		if !ok || platform == "" || path == "" {
+			return nil, fmt.Errorf("invalid prebuilt binary %q: must be <platform>=<path>", value)
+		}
+		if _, ok := m[platform]; ok {
+			return nil, fmt.Errorf("invalid prebuilt binary %q: duplicate platform %s", value, platform)
+		}
+		m[platform] = path
+	}
+	return m, nil
+}
+
 func parseLabels(labels []string) (map[string]string, error) {
 	m := make(map[string]string, len(labels))
 	for _, label := range labels {
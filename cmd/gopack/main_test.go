@@ -16,8 +16,11 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
 )
 
 func TestBuildRequiresOutput(t *testing.T) {
@@ -40,6 +43,240 @@ func TestLoadRejectsUnsupportedDaemonBeforeOtherWork(t *testing.T) {
 	}
 }
 
+func TestRejectsUnsupportedFormat(t *testing.T) {
+	_, err := executeCommand("publish", "/path/that/does/not/exist", "--format", "yaml")
+	if err == nil {
+		t.Fatal("command error = nil, want unsupported format error")
+	}
+	if !strings.Contains(err.Error(), `unsupported format "yaml"`) {
+		t.Fatalf("command error = %q, want unsupported format error", err)
+	}
+}
+
+func TestRejectsQuietWithVerbose(t *testing.T) {
+	_, err := executeCommand("publish", "/path/that/does/not/exist", "-q", "-v")
+	if err == nil {
+		t.Fatal("command error = nil, want conflicting log flags error")
+	}
+	if !strings.Contains(err.Error(), "cannot use --quiet with --verbose") {
+		t.Fatalf("command error = %q, want conflicting log flags error", err)
+	}
+}
+
+func TestRejectsInvalidImmutableTagPattern(t *testing.T) {
+	for _, args := range [][]string{{"--immutable-tags=v[0-9"}, {"--immutable-tags", "v[0-9"}} {
+		_, err := executeCommand(append([]string{"publish", "/path/that/does/not/exist"}, args...)...)
+		if err == nil {
+			t.Fatal("command error = nil, want invalid pattern error")
+		}
+		if !strings.Contains(err.Error(), `invalid immutable tag pattern "v[0-9"`) {
+			t.Fatalf("command error = %q, want invalid pattern error", err)
+		}
+	}
+}
+
+func TestRejectsInvalidSizeBudget(t *testing.T) {
+	_, err := executeCommand("publish", "/path/that/does/not/exist", "--max-image-size=50XB")
+	if err == nil || !strings.Contains(err.Error(), `invalid max image size: invalid size "50XB"`) {
+		t.Fatalf("command error = %v, want invalid size error", err)
+	}
+}
+
+func TestParsePrebuilt(t *testing.T) {
+	got, err := parsePrebuilt([]string{"linux/amd64=./dist/app_amd64", "linux/arm64=./dist/app_arm64"})
+	if err != nil {
+		t.Fatalf("parsePrebuilt() error = %v", err)
+	}
+	if len(got) != 2 || got["linux/arm64"] != "./dist/app_arm64" {
+		t.Fatalf("parsePrebuilt() = %v", got)
+	}
+
+	for _, value := range []string{"linux/amd64", "=./app", "linux/amd64="} {
+		if _, err := parsePrebuilt([]string{value}); err == nil {
+			t.Fatalf("parsePrebuilt(%q) error = nil, want error", value)
+		}
+	}
+	if _, err := parsePrebuilt([]string{"linux/amd64=a", "linux/amd64=b"}); err == nil {
+		t.Fatal("parsePrebuilt() error = nil, want duplicate platform error")
+	}
+}
+
+func TestSplitPlatformValue(t *testing.T) {
+	tests := []struct {
+		value, platform, v string
+		ok                 bool
+	}{
+		{value: "example.com/base:latest", v: "example.com/base:latest"},
+		{value: "linux/arm/v6=example.com/base:armv6", platform: "linux/arm/v6", v: "example.com/base:armv6", ok: true},
+		{value: "-s -w -X example.com/app/version.V=1", v: "-s -w -X example.com/app/version.V=1"},
+		{value: "linux/amd64=-s -w -X main.v=1", platform: "linux/amd64", v: "-s -w -X main.v=1", ok: true},
+		{value: "CC=clang", v: "CC=clang"},
+		{value: "linux/arm64=CC=aarch64-linux-gnu-gcc", platform: "linux/arm64", v: "CC=aarch64-linux-gnu-gcc", ok: true},
+		{value: "PATH /usr/bin=x", v: "PATH /usr/bin=x"},
+	}
+	for _, test := range tests {
+		platform, v, ok := splitPlatformValue(test.value)
+		if platform != test.platform || v != test.v || ok != test.ok {
+			t.Errorf("splitPlatformValue(%q) = %q, %q, %t, want %q, %q, %t", test.value, platform, v, ok, test.platform, test.v, test.ok)
+		}
+	}
+}
+
+func TestBuildPlatformOptions(t *testing.T) {
+	opts := defaultCLIOptions()
+	opts.base = []string{"example.com/base", "linux/arm/v6=example.com/base:armv6"}
+	opts.ldflags = []string{"-s -w", "linux/arm/v6=-s"}
+	opts.buildTags = []string{"netgo,osusergo", "linux/arm/v6=slow"}
+	opts.env = []string{"CC=clang", "linux/arm/v6=GOFLAGS=-p=1"}
+	options, err := buildPlatformOptions(opts)
+	if err != nil {
+		t.Fatalf("buildPlatformOptions() error = %v", err)
+	}
+	if len(options) != 5 {
+		t.Fatalf("buildPlatformOptions() returned %d options, want 5", len(options))
+	}
+
+	tests := []struct {
+		base, ldflags []string
+		want          string
+	}{
+		{base: []string{"a", "b"}, want: `the base is already "a"`},
+		{base: []string{"linux/arm/v6=a", "linux/arm/v6=b"}, want: "duplicate platform linux/arm/v6"},
+		{base: []string{"linux/ad64=a"}, want: `unsupported platform "linux/ad64"`},
+		{ldflags: []string{"-s", "-w"}, want: `the ldflags are already "-s"`},
+		{ldflags: []string{"linux/amd64=-s", "linux/amd64=-w"}, want: "duplicate platform linux/amd64"},
+	}
+	for _, test := range tests {
+		opts := defaultCLIOptions()
+		opts.base, opts.ldflags = test.base, test.ldflags
+		_, err := buildPlatformOptions(opts)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("buildPlatformOptions(%q, %q) error = %v, want %q", test.base, test.ldflags, err, test.want)
+		}
+	}
+}
+
+func TestPackagesPattern(t *testing.T) {
+	tests := []struct {
+		since   string
+		args    []string
+		pattern string
+		ok      bool
+	}{
+		{args: nil, pattern: ".", ok: false},
+		{args: []string{"./cmd/app"}, pattern: "./cmd/app", ok: false},
+		{args: []string{"./cmd/..."}, pattern: "./cmd/...", ok: true},
+		{since: "origin/main", args: nil, pattern: ".", ok: true},
+		{since: "origin/main", args: []string{"./cmd/app"}, pattern: "./cmd/app", ok: true},
+	}
+	for _, test := range tests {
+		opts := defaultCLIOptions()
+		opts.since = test.since
+		pattern, ok := packagesPattern(opts, test.args)
+		if pattern != test.pattern || ok != test.ok {
+			t.Errorf("packagesPattern(%q, %v) = %q, %t, want %q, %t", test.since, test.args, pattern, ok, test.pattern, test.ok)
+		}
+	}
+}
+
+func TestParseMirrors(t *testing.T) {
+	m, err := parseMirrors([]string{"docker.io=mirror.example.com", "ghcr.io=localhost:5000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m["docker.io"]; got != "mirror.example.com" {
+		t.Fatalf("mirror for docker.io = %q, want mirror.example.com", got)
+	}
+
+	for _, invalid := range []string{"docker.io", "=mirror", "docker.io="} {
+		if _, err := parseMirrors([]string{invalid}); err == nil {
+			t.Fatalf("parseMirrors(%q) error = nil, want invalid mirror error", invalid)
+		}
+	}
+}
+
+func TestParseRegistryAuth(t *testing.T) {
+	t.Setenv("GOPACK_REGISTRY_USERNAME", "user")
+	t.Setenv("GOPACK_REGISTRY_PASSWORD", "pass")
+
+	m, err := parseRegistryAuth([]string{
+		"ghcr.io=env",
+		"registry.local=token-file:/run/secrets/token",
+		"123.dkr.ecr.us-east-1.amazonaws.com=helper:ecr-login",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m["ghcr.io"]; got.Username != "user" || got.Password != "pass" {
+		t.Fatalf("ghcr.io credential = %+v, want env credentials", got)
+	}
+	if got := m["registry.local"].TokenFile; got != "/run/secrets/token" {
+		t.Fatalf("registry.local token file = %q", got)
+	}
+	if got := m["123.dkr.ecr.us-east-1.amazonaws.com"].Helper; got != "ecr-login" {
+		t.Fatalf("ecr helper = %q", got)
+	}
+
+	for _, invalid := range []string{"ghcr.io", "ghcr.io=password", "ghcr.io=helper:"} {
+		if _, err := parseRegistryAuth([]string{invalid}); err == nil {
+			t.Fatalf("parseRegistryAuth(%q) error = nil, want error", invalid)
+		}
+	}
+}
+
+func TestLoginCredentials(t *testing.T) {
+	opts := &loginOptions{username: "user", passwordStdin: true}
+	username, password, err := loginCredentials(opts, strings.NewReader("secret\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "user" || password != "secret" {
+		t.Fatalf("loginCredentials() = %q, %q, want user, secret", username, password)
+	}
+
+	t.Setenv("GOPACK_REGISTRY_USERNAME", "")
+	if _, _, err := loginCredentials(&loginOptions{password: "secret"}, nil); err == nil {
+		t.Fatal("loginCredentials() error = nil, want missing username error")
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	res := &gopack.Result{
+		Reference: "example.com/app:v1",
+		Digest:    "sha256:abc",
+		Tags:      []string{"example.com/app:v1", "example.com/app:latest"},
+	}
+
+	var text bytes.Buffer
+	if err := writeResult(&text, formatText, res); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := text.String(), "example.com/app:v1\n"; got != want {
+		t.Fatalf("text output = %q, want %q", got, want)
+	}
+
+	var raw bytes.Buffer
+	if err := writeResult(&raw, formatJSON, res); err != nil {
+		t.Fatal(err)
+	}
+	var decoded gopack.Result
+	if err := json.Unmarshal(raw.Bytes(), &decoded); err != nil {
+		t.Fatalf("decoding JSON output: %v", err)
+	}
+	if decoded.Digest != res.Digest || len(decoded.Tags) != 2 {
+		t.Fatalf("decoded result = %+v, want %+v", decoded, res)
+	}
+
+	res.References = []string{"example.com/app:v1", "dr.example.com/app:v1"}
+	text.Reset()
+	if err := writeResult(&text, formatText, res); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := text.String(), "example.com/app:v1\ndr.example.com/app:v1\n"; got != want {
+		t.Fatalf("text output = %q, want %q", got, want)
+	}
+}
+
 func executeCommand(args ...string) (string, error) {
 	cmd := newRootCmd()
 	buf := new(bytes.Buffer)
@@ -50,6 +287,36 @@ func executeCommand(args ...string) (string, error) {
 	return buf.String(), err
 }
 
+func TestWritePlan(t *testing.T) {
+	res := &gopack.Result{
+		DryRun: gopack.DryRunPlan,
+		Base:   gopack.BaseResult{Reference: "example.com/base:latest", Digest: "sha256:base"},
+		Images: []gopack.ImageResult{
+			{Platform: "linux/amd64", Base: "sha256:amd64"},
+			{Platform: "linux/arm/v6", Base: "sha256:armv6", BaseReference: "example.com/base:armv6", Tags: []string{"example.com/app:v1-linux-arm-v6"}},
+		},
+		Tags: []string{"example.com/app:v1", "dr.example.com/app:v1"},
+	}
+
+	var out bytes.Buffer
+	if err := writeResult(&out, formatText, res); err != nil {
+		t.Fatal(err)
+	}
+	want := `Dry run (plan): nothing was written
+Base: example.com/base:latest@sha256:base
+Images:
+  linux/amd64  base sha256:amd64
+  linux/arm/v6  base example.com/base:armv6@sha256:armv6
+Destinations:
+  example.com/app:v1
+  dr.example.com/app:v1
+  example.com/app:v1-linux-arm-v6
+`
+	if out.String() != want {
+		t.Fatalf("plan output = %q, want %q", out.String(), want)
+	}
+}
+
 func TestIsValidLabelKey(t *testing.T) {
 	tests := []struct {
 		key   string
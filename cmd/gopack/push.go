@@ -0,0 +1,64 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+
+	"github.com/spf13/cobra"
+)
+
+func newPushCommand() *cobra.Command {
+	opts := defaultCLIOptions()
+	cmd := &cobra.Command{
+		Use:   "push <oci:path>",
+		Short: "Push an OCI archive written by gopack build without rebuilding",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateLogOptions(opts); err != nil {
+				return err
+			}
+			if err := validateFormat(opts.format); err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			logger := newLogger(cmd.ErrOrStderr(), opts)
+			options, err := buildRegistryOptions(opts)
+			if err != nil {
+				return err
+			}
+			options = append(options, buildPushOptions(opts)...)
+			options = append(options, gopack.WithLogger(logger))
+
+			res, err := gopack.Push(ctx, args[0], options...)
+			if err != nil {
+				return reportError(cmd, opts, logger, withAuthHint(err))
+			}
+			return writeOutputs(cmd, opts, res)
+		},
+	}
+	addPushFlags(cmd, opts)
+	addLogFlags(cmd, opts)
+	addRegistryFlags(cmd, opts)
+	return cmd
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPushRejectsUnsupportedArchive(t *testing.T) {
+	_, err := executeCommand("push", "./image.tar", "-r", "example.com/app")
+	if err == nil || !strings.Contains(err.Error(), "unsupported archive") {
+		t.Fatalf("command error = %v, want unsupported archive error", err)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package git reads information about the git checkout gopack runs in.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoTag is returned by Tags when no tag points at HEAD.
+var ErrNoTag = errors.New("no git tag points at HEAD")
+
+// branchEnv lists the variables CI systems use to expose the branch name when
+// the checkout has a detached HEAD.
+var branchEnv = []string{
+	"GITHUB_HEAD_REF",
+	"GITHUB_REF_NAME",
+	"CI_COMMIT_REF_NAME",
+	"BUILDKITE_BRANCH",
+}
+
+// Commit returns the full hash of the HEAD commit in dir.
+func Commit(ctx context.Context, dir string) (string, error) {
+	return run(ctx, dir, "rev-parse", "HEAD")
+}
+
+// Branch returns the name of the branch checked out in dir. If HEAD is
+// detached, the branch is read from common CI environment variables.
+func Branch(ctx context.Context, dir string) (string, error) {
+	branch, err := run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if branch != "HEAD" {
+		return branch, nil
+	}
+	for _, key := range branchEnv {
+		if v := os.Getenv(key); v != "" {
+			return v, nil
+		}
+	}
+	return "", errors.New("git: HEAD is detached and no branch is set in the environment")
+}
+
+// Tags returns the tags pointing at HEAD in dir, highest version first.
+func Tags(ctx context.Context, dir string) ([]string, error) {
+	out, err := run(ctx, dir, "tag", "--points-at", "HEAD", "--sort=-v:refname")
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, ErrNoTag
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// ChangedFiles returns the absolute paths of the files in dir's repository
+// that differ between the merge base of ref and HEAD and the working tree,
+// including untracked files. Commits on ref since HEAD branched off are not
+// changes. Renamed files are reported by both their old and new paths.
+func ChangedFiles(ctx context.Context, dir, ref string) ([]string, error) {
+	root, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	base, err := run(ctx, dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := run(ctx, dir, "diff", "-z", "--name-only", "--no-renames", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run(ctx, root, "ls-files", "-z", "--others", "--exclude-standard", "--full-name")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, out := range []string{diff, untracked} {
+		for _, name := range strings.Split(out, "\x00") {
+			if name == "" {
+				continue
+			}
+			files = append(files, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRepositoryInfo(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+
+	commit, err := Commit(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commit) != 40 {
+		t.Fatalf("Commit() = %q, want full hash", commit)
+	}
+
+	branch, err := Branch(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "main" {
+		t.Fatalf("Branch() = %q, want main", branch)
+	}
+
+	if _, err := Tags(ctx, dir); !errors.Is(err, ErrNoTag) {
+		t.Fatalf("Tags() error = %v, want ErrNoTag", err)
+	}
+	runTestGit(t, dir, "tag", "v1.9.0")
+	runTestGit(t, dir, "tag", "v1.10.0")
+	tags, err := Tags(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"v1.10.0", "v1.9.0"}; !slices.Equal(tags, want) {
+		t.Fatalf("Tags() = %v, want %v", tags, want)
+	}
+}
+
+func TestBranchDetached(t *testing.T) {
+	dir := initTestRepo(t)
+	runTestGit(t, dir, "checkout", "-q", "--detach")
+
+	for _, key := range branchEnv {
+		t.Setenv(key, "")
+	}
+	if _, err := Branch(context.Background(), dir); err == nil {
+		t.Fatal("Branch() error = nil, want detached HEAD error")
+	}
+
+	t.Setenv("GITHUB_REF_NAME", "release")
+	branch, err := Branch(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "release" {
+		t.Fatalf("Branch() = %q, want release", branch)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	writeTestFile(t, filepath.Join(dir, "a", "a.go"))
+	writeTestFile(t, filepath.Join(dir, "b", "b.go"))
+	runTestGit(t, dir, "add", ".")
+	runTestGit(t, dir, "commit", "-q", "-m", "add files")
+	runTestGit(t, dir, "tag", "base")
+
+	runTestGit(t, dir, "mv", "a/a.go", "a/renamed.go")
+	runTestGit(t, dir, "commit", "-q", "-m", "rename")
+	writeTestFile(t, filepath.Join(dir, "c", "c.go"))
+
+	files, err := ChangedFiles(context.Background(), filepath.Join(dir, "b"), "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(files)
+	want := []string{
+		filepath.Join(dir, "a", "a.go"),
+		filepath.Join(dir, "a", "renamed.go"),
+		filepath.Join(dir, "c", "c.go"),
+	}
+	if !slices.Equal(files, want) {
+		t.Fatalf("ChangedFiles() = %v, want %v", files, want)
+	}
+
+	if _, err := ChangedFiles(context.Background(), dir, "missing"); err == nil {
+		t.Fatal("ChangedFiles() error = nil, want unknown revision error")
+	}
+}
+
+func TestChangedFilesDivergedBranch(t *testing.T) {
+	dir := initTestRepo(t)
+	runTestGit(t, dir, "checkout", "-q", "-b", "feature")
+	writeTestFile(t, filepath.Join(dir, "feature.go"))
+	runTestGit(t, dir, "add", ".")
+	runTestGit(t, dir, "commit", "-q", "-m", "feature")
+
+	runTestGit(t, dir, "checkout", "-q", "main")
+	writeTestFile(t, filepath.Join(dir, "main.go"))
+	runTestGit(t, dir, "add", ".")
+	runTestGit(t, dir, "commit", "-q", "-m", "main")
+	runTestGit(t, dir, "checkout", "-q", "feature")
+	writeTestFile(t, filepath.Join(dir, "local.go"))
+
+	files, err := ChangedFiles(context.Background(), dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(files)
+	want := []string{filepath.Join(dir, "feature.go"), filepath.Join(dir, "local.go")}
+	if !slices.Equal(files, want) {
+		t.Fatalf("ChangedFiles() = %v, want %v", files, want)
+	}
+}
+
+// initTestRepo creates a git repository with a single commit on the main
+// branch in a temporary directory.
+func initTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "-q", "-b", "main")
+	runTestGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+// runTestGit runs git in dir with a fixed identity, failing the test on
+// error.
+func runTestGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -44,28 +45,30 @@ var goArchTuningEnv = []string{
 }
 
 func New(options ...Option) *GoBuilder {
-	opts := defaultOptions()
-	for _, o := range options {
-		o(opts)
-	}
-	return &GoBuilder{opts: *opts}
+	return &GoBuilder{opts: *newOptions(options)}
 }
 
-func (b *GoBuilder) GoBuild(ctx context.Context, outPath string, platform types.Platform) error {
+// Build compiles the main package for platform to outPath.
+func (b *GoBuilder) Build(ctx context.Context, outPath string, platform types.Platform) error {
+	opts := b.opts.forPlatform(platform)
 	args := []string{"build"}
-	if b.opts.trimpathEnabled {
+	if opts.trimpathEnabled {
 		args = append(args, "-trimpath")
 	}
-	if b.opts.ldflags != "" {
-		args = append(args, "-ldflags", b.opts.ldflags)
+	if opts.ldflags != "" {
+		args = append(args, "-ldflags", opts.ldflags)
+	}
+	if len(opts.buildTags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.buildTags, ","))
 	}
-	if b.opts.modFlag != "" {
-		args = append(args, "-mod", b.opts.modFlag)
+	if opts.modFlag != "" {
+		args = append(args, "-mod", opts.modFlag)
 	}
 	args = append(args, "-o", outPath)
-	args = append(args, b.opts.mainPath)
+	args = append(args, opts.mainPath)
 
-	cmd := exec.CommandContext(ctx, b.opts.goBin, args...)
+	cmd := exec.CommandContext(ctx, opts.goBin, args...)
+	cmd.Dir = opts.dir
 	cmd.Env = b.env(platform)
 
 	var stdout bytes.Buffer
@@ -86,20 +89,40 @@ func (b *GoBuilder) GoBuild(ctx context.Context, outPath string, platform types.
 }
 
 func (b *GoBuilder) env(platform types.Platform) []string {
+	return b.opts.forPlatform(platform).goEnv(platform)
+}
+
+// goEnv returns the environment of go commands targeting platform.
+func (o options) goEnv(platform types.Platform) []string {
+	envMap := o.baseEnv(platform)
+	envMap["CGO_ENABLED"] = "0"
+	if o.cgoEnabled {
+		envMap["CGO_ENABLED"] = "1"
+	}
+	return envList(envMap)
+}
+
+// baseEnv returns the process environment with the user's variables applied,
+// then GOOS, GOARCH, the architecture variables and GOWORK set for platform.
+func (o options) baseEnv(platform types.Platform) map[string]string {
 	envMap := make(map[string]string)
-	for _, e := range os.Environ() {
-		if before, after, ok := strings.Cut(e, "="); ok {
-			envMap[before] = after
+	for _, env := range [][]string{os.Environ(), o.env} {
+		for _, e := range env {
+			if before, after, ok := strings.Cut(e, "="); ok {
+				envMap[before] = after
+			}
 		}
 	}
 	envMap["GOOS"] = platform.OS()
 	envMap["GOARCH"] = platform.Arch()
 	setTargetArchEnv(envMap, platform)
-	envMap["CGO_ENABLED"] = "0"
-	if b.opts.cgoEnabled {
-		envMap["CGO_ENABLED"] = "1"
+	if o.goWork != "" {
+		envMap["GOWORK"] = o.goWork
 	}
+	return envMap
+}
 
+func envList(envMap map[string]string) []string {
 	out := make([]string, 0, len(envMap))
 	for k, v := range envMap {
 		out = append(out, k+"="+v)
@@ -142,3 +165,19 @@ func variantNumber(platform types.Platform) (int, bool) {
 	}
 	return 0, false
 }
+
+// CheckEnv returns an error if an environment variable is not KEY=VALUE or
+// sets a variable that is derived from the platform and options: GOOS,
+// GOARCH, the architecture variables and CGO_ENABLED.
+func CheckEnv(env []string) error {
+	for _, e := range env {
+		key, _, ok := strings.Cut(e, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid env %q: must be KEY=VALUE", e)
+		}
+		if key == "GOOS" || key == "GOARCH" || key == "CGO_ENABLED" || slices.Contains(goArchTuningEnv, key) {
+			return fmt.Errorf("invalid env %q: %s is set from the platform and options", e, key)
+		}
+	}
+	return nil
+}
@@ -76,6 +76,44 @@ func TestEnvCGOEnabled(t *testing.T) {
 	}
 }
 
+func TestEnvPlatformOptions(t *testing.T) {
+	t.Setenv("CC", "gcc")
+	armv6 := types.ParsePlatform("linux/arm/v6")
+	b := New(
+		WithEnv([]string{"CC=clang", "GOFLAGS=-buildvcs=false"}),
+		WithPlatformOptions(map[types.Platform]PlatformOptions{
+			armv6: {Env: []string{"CC=arm-linux-gnueabi-gcc"}},
+		}),
+	)
+
+	m := envToMap(t, b.env(types.ParsePlatform("linux/amd64")))
+	if m["CC"] != "clang" || m["GOFLAGS"] != "-buildvcs=false" {
+		t.Errorf("env = %v, want shared variables applied", m)
+	}
+	m = envToMap(t, b.env(armv6))
+	if m["CC"] != "arm-linux-gnueabi-gcc" || m["GOFLAGS"] != "-buildvcs=false" || m["GOARM"] != "6" {
+		t.Errorf("env = %v, want platform variables applied", m)
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	if err := CheckEnv([]string{"CC=clang", "EMPTY="}); err != nil {
+		t.Fatalf("CheckEnv() error = %v", err)
+	}
+	for env, want := range map[string]string{
+		"CC":            "must be KEY=VALUE",
+		"=x":            "must be KEY=VALUE",
+		"GOOS=darwin":   "GOOS is set from the platform",
+		"GOARM=5":       "GOARM is set from the platform",
+		"CGO_ENABLED=1": "CGO_ENABLED is set from the platform",
+	} {
+		err := CheckEnv([]string{env})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("CheckEnv(%q) error = %v, want %q", env, err, want)
+		}
+	}
+}
+
 func TestEnvVariants(t *testing.T) {
 	tests := []struct {
 		platform string
@@ -0,0 +1,223 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Package is a package as reported by go list.
+type Package struct {
+	Name       string
+	ImportPath string
+	Dir        string
+	Standard   bool
+	DepOnly    bool
+	Deps       []string
+	EmbedFiles []string
+	Module     *Module
+}
+
+// Module is the module containing a package, as reported by go list.
+type Module struct {
+	Path  string
+	Dir   string
+	GoMod string
+}
+
+// majorVersionSuffix matches the final element of an import path that only
+// denotes the major version of a module, such as "v2". As with go install,
+// "v0" and "v1" are not major version suffixes.
+var majorVersionSuffix = regexp.MustCompile(`^v([2-9]|[1-9][0-9]+)$`)
+
+// BinName returns the name go install gives the binary of the main package at
+// importPath: its last element, unless that is a major version suffix.
+func BinName(importPath string) string {
+	importPath = strings.TrimSuffix(importPath, "/")
+	name := path.Base(importPath)
+	if majorVersionSuffix.MatchString(name) {
+		if parent := path.Dir(importPath); parent != "." {
+			name = path.Base(parent)
+		}
+	}
+	return name
+}
+
+// ModuleRoot returns the root directory of the module containing dir, which
+// is the nearest directory at or above it with a go.mod file.
+func ModuleRoot(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if stat, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !stat.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ResolveMain resolves importPath with go list and returns an error unless it
+// is a main package.
+func ResolveMain(ctx context.Context, importPath string, options ...Option) (*Package, error) {
+	opts := newOptions(options)
+	args := []string{"list", "-json"}
+	if opts.modFlag != "" {
+		args = append(args, "-mod", opts.modFlag)
+	}
+	out, err := runGo(ctx, opts, append(args, "--", importPath)...)
+	if err != nil {
+		return nil, err
+	}
+	var pkg Package
+	if err := json.Unmarshal(out, &pkg); err != nil {
+		return nil, fmt.Errorf("go list: %w", err)
+	}
+	if pkg.Name != "main" {
+		return nil, fmt.Errorf("%s is not a main package", importPath)
+	}
+	return &pkg, nil
+}
+
+// NewTempModule creates a temporary module that requires importPath at
+// version, so that the package can be built from it the way go install
+// importPath@version does. Like go install, workspaces are ignored. The
+// caller must remove the returned directory.
+func NewTempModule(ctx context.Context, importPath, version string, options ...Option) (string, error) {
+	dir, err := os.MkdirTemp("", "gopack-module-")
+	if err != nil {
+		return "", err
+	}
+	gomod := []byte("module gopack.local/build\n")
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), gomod, 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	opts := newOptions(append(options, WithDir(dir), WithGoWork("off")))
+	if _, err := runGo(ctx, opts, "get", "--", importPath+"@"+version); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// Workspace returns the path of the go.work file used in the directory the
+// compiler runs in, or an empty string if workspaces are not in use.
+func Workspace(ctx context.Context, options ...Option) (string, error) {
+	out, err := runGo(ctx, newOptions(options), "env", "GOWORK")
+	if err != nil {
+		return "", err
+	}
+	work := strings.TrimSpace(string(out))
+	if work == "off" {
+		return "", nil
+	}
+	return work, nil
+}
+
+// CheckModFlag returns an error if the -mod flag cannot be used in the
+// directory the compiler runs in. Workspaces only support -mod=readonly and
+// -mod=vendor, and -mod=vendor requires the workspace or module to be
+// vendored.
+func CheckModFlag(ctx context.Context, options ...Option) error {
+	opts := newOptions(options)
+	switch opts.modFlag {
+	case "":
+		return nil
+	case "mod", "readonly", "vendor":
+	default:
+		return fmt.Errorf("unsupported -mod %q (supported: mod, readonly, vendor)", opts.modFlag)
+	}
+
+	work, err := Workspace(ctx, options...)
+	if err != nil {
+		return err
+	}
+	if work != "" {
+		switch opts.modFlag {
+		case "mod":
+			return fmt.Errorf("cannot use -mod=mod with workspace %s; use -mod=readonly or disable the workspace with GOWORK=off", work)
+		case "vendor":
+			if !isVendored(filepath.Dir(work)) {
+				return fmt.Errorf("cannot use -mod=vendor: workspace %s is not vendored; run go work vendor", work)
+			}
+		}
+		return nil
+	}
+
+	if opts.modFlag == "vendor" {
+		root, ok := ModuleRoot(cmp.Or(opts.dir, "."))
+		if !ok {
+			return errors.New("cannot use -mod=vendor outside of a module")
+		}
+		if !isVendored(root) {
+			return fmt.Errorf("cannot use -mod=vendor: module %s is not vendored; run go mod vendor", root)
+		}
+	}
+	return nil
+}
+
+func isVendored(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "vendor", "modules.txt"))
+	return err == nil
+}
+
+func newOptions(options []Option) *options {
+	opts := defaultOptions()
+	for _, o := range options {
+		o(opts)
+	}
+	return opts
+}
+
+func runGo(ctx context.Context, opts *options, args ...string) ([]byte, error) {
+	var env []string
+	if opts.goWork != "" {
+		env = append(os.Environ(), "GOWORK="+opts.goWork)
+	}
+	return runGoEnv(ctx, opts, env, args...)
+}
+
+// runGoEnv runs the go command with env, or with the process environment if
+// env is nil.
+func runGoEnv(ctx context.Context, opts *options, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, opts.goBin, args...)
+	cmd.Dir = opts.dir
+	cmd.Env = env
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("go %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
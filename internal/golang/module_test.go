@@ -0,0 +1,133 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/testutil"
+)
+
+func TestBinName(t *testing.T) {
+	tests := map[string]string{
+		"github.com/org/repo/cmd/tool":    "tool",
+		"golang.org/x/tools/cmd/stringer": "stringer",
+		"example.com/tool/v2":             "tool",
+		"example.com/tool/v10":            "tool",
+		"example.com/tool/v1":             "v1",
+		"example.com/tool/v02":            "v02",
+		"v2":                              "v2",
+	}
+	for importPath, want := range tests {
+		if got := BinName(importPath); got != want {
+			t.Errorf("BinName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestTempModule(t *testing.T) {
+	testutil.UseModuleProxy(t, "example.com/tool", "v1.0.0", map[string]string{
+		"go.mod":            "module example.com/tool\n\ngo 1.21\n",
+		"cmd/hello/main.go": "package main\n\nfunc main() {}\n",
+		"lib/lib.go":        "package lib\n",
+	})
+
+	dir, err := NewTempModule(context.Background(), "example.com/tool/cmd/hello", "v1.0.0")
+	if err != nil {
+		t.Fatalf("NewTempModule() error = %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	pkg, err := ResolveMain(context.Background(), "example.com/tool/cmd/hello", WithDir(dir))
+	if err != nil {
+		t.Fatalf("ResolveMain() error = %v", err)
+	}
+	if pkg.ImportPath != "example.com/tool/cmd/hello" || !strings.Contains(pkg.Dir, "example.com/tool@v1.0.0") {
+		t.Fatalf("ResolveMain() = %+v, want package from the module cache", pkg)
+	}
+
+	_, err = ResolveMain(context.Background(), "example.com/tool/lib", WithDir(dir))
+	if err == nil || !strings.Contains(err.Error(), "example.com/tool/lib is not a main package") {
+		t.Fatalf("ResolveMain() error = %v, want not a main package error", err)
+	}
+
+	_, err = NewTempModule(context.Background(), "example.com/tool/cmd/hello", "v9.9.9")
+	if err == nil || !strings.Contains(err.Error(), "go get") {
+		t.Fatalf("NewTempModule() error = %v, want go get error", err)
+	}
+}
+
+func TestModuleRoot(t *testing.T) {
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"services/api/go.mod":          "module example.com/api\n",
+		"services/api/cmd/api/main.go": "package main\n",
+	})
+
+	root, ok := ModuleRoot(filepath.Join(dir, "services", "api", "cmd", "api"))
+	if !ok || root != filepath.Join(dir, "services", "api") {
+		t.Fatalf("ModuleRoot() = %q, %t, want services/api", root, ok)
+	}
+	if root, ok := ModuleRoot(filepath.Join(dir, "services")); ok {
+		t.Fatalf("ModuleRoot() = %q, want no module", root)
+	}
+}
+
+func TestCheckModFlag(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"go.work":                     "go 1.21\n\nuse ./api\n",
+		"api/go.mod":                  "module example.com/api\n\ngo 1.21\n",
+		"single/go.mod":               "module example.com/single\n\ngo 1.21\n",
+		"vendored/go.mod":             "module example.com/vendored\n\ngo 1.21\n",
+		"vendored/vendor/modules.txt": "",
+	})
+	api := filepath.Join(dir, "api")
+
+	tests := []struct {
+		name    string
+		options []Option
+		wantErr string
+	}{
+		{name: "no flag", options: []Option{WithDir(api)}},
+		{name: "unsupported", options: []Option{WithDir(api), WithModFlag("other")}, wantErr: "unsupported -mod"},
+		{name: "workspace readonly", options: []Option{WithDir(api), WithModFlag("readonly")}},
+		{name: "workspace mod", options: []Option{WithDir(api), WithModFlag("mod")}, wantErr: "cannot use -mod=mod with workspace"},
+		{name: "workspace vendor", options: []Option{WithDir(api), WithModFlag("vendor")}, wantErr: "run go work vendor"},
+		{name: "workspace off", options: []Option{WithDir(api), WithModFlag("mod"), WithGoWork("off")}},
+		{name: "module vendor", options: []Option{WithDir(filepath.Join(dir, "single")), WithModFlag("vendor"), WithGoWork("off")}, wantErr: "run go mod vendor"},
+		{name: "vendored module", options: []Option{WithDir(filepath.Join(dir, "vendored")), WithModFlag("vendor"), WithGoWork("off")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckModFlag(context.Background(), test.options...)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckModFlag() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("CheckModFlag() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -14,20 +14,76 @@
 
 package golang
 
+import (
+	"slices"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
 type Option func(*options)
 
+// PlatformOptions overrides the build options of a single platform.
+type PlatformOptions struct {
+	// LDFlags replaces the ldflags, if set.
+	LDFlags string
+	// BuildTags are added to the build tags.
+	BuildTags []string
+	// Env is added to the build environment, as KEY=VALUE.
+	Env []string
+}
+
+// WithBuildTags sets the build tags passed to the compiler.
+func WithBuildTags(v []string) Option {
+	return func(o *options) {
+		o.buildTags = v
+	}
+}
+
 func WithCGOEnabled(v bool) Option {
 	return func(o *options) {
 		o.cgoEnabled = v
 	}
 }
 
+// WithDir sets the directory the compiler runs in, which determines the main
+// module. The default is the current directory.
+func WithDir(v string) Option {
+	return func(o *options) {
+		o.dir = v
+	}
+}
+
+// WithEnv sets additional environment variables, as KEY=VALUE, for every
+// build. GOOS, GOARCH, the architecture variables and CGO_ENABLED are always
+// set from the platform and options.
+func WithEnv(v []string) Option {
+	return func(o *options) {
+		o.env = v
+	}
+}
+
 func WithGoBin(v string) Option {
 	return func(o *options) {
 		o.goBin = v
 	}
 }
 
+// WithTinyGoBin sets the tinygo executable used by TinyGoBuilder.
+func WithTinyGoBin(v string) Option {
+	return func(o *options) {
+		o.tinygoBin = v
+	}
+}
+
+// WithGoWork sets GOWORK for the go commands run, either the path of a
+// go.work file or "off" to disable workspaces. By default, GOWORK is
+// inherited from the environment.
+func WithGoWork(v string) Option {
+	return func(o *options) {
+		o.goWork = v
+	}
+}
+
 func WithLDFlags(v string) Option {
 	return func(o *options) {
 		o.ldflags = v
@@ -46,6 +102,13 @@ func WithModFlag(v string) Option {
 	}
 }
 
+// WithPlatformOptions sets per-platform overrides of the build options.
+func WithPlatformOptions(v map[types.Platform]PlatformOptions) Option {
+	return func(o *options) {
+		o.platformOptions = v
+	}
+}
+
 func WithTrimpath(v bool) Option {
 	return func(o *options) {
 		o.trimpathEnabled = v
@@ -53,21 +116,46 @@ func WithTrimpath(v bool) Option {
 }
 
 type options struct {
+	buildTags       []string
 	cgoEnabled      bool
+	dir             string
+	env             []string
 	goBin           string
+	goWork          string
 	ldflags         string
 	mainPath        string
 	modFlag         string
+	platformOptions map[types.Platform]PlatformOptions
+	tinygoBin       string
 	trimpathEnabled bool
 }
 
 func defaultOptions() *options {
 	return &options{
+		buildTags:       nil,
 		cgoEnabled:      false,
+		dir:             "",
 		goBin:           "go",
+		goWork:          "",
 		ldflags:         "-s -w",
 		mainPath:        ".",
 		modFlag:         "",
+		tinygoBin:       "tinygo",
 		trimpathEnabled: true,
 	}
 }
+
+// forPlatform returns the options for building platform, with its overrides
+// applied.
+func (o options) forPlatform(platform types.Platform) options {
+	po, ok := o.platformOptions[platform]
+	if !ok {
+		return o
+	}
+	if po.LDFlags != "" {
+		o.ldflags = po.LDFlags
+	}
+	o.buildTags = append(slices.Clip(o.buildTags), po.BuildTags...)
+	o.env = append(slices.Clip(o.env), po.Env...)
+	return o
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// MainPackage is a main package matched by a pattern, with the files in the
+// file system that its build depends on.
+type MainPackage struct {
+	Package
+
+	// Dirs are the directories of the package and of its non-standard
+	// dependencies.
+	Dirs []string
+	// Files are the other files the build depends on: embedded files, the
+	// go.mod and go.sum files of the modules providing the packages, and the
+	// go.work and go.work.sum files of the workspace, if any.
+	Files []string
+}
+
+// DependsOn reports whether the build of the package depends on the file at
+// the absolute path, either because it is in the directory of one of its
+// packages or because it is one of its other inputs.
+func (p *MainPackage) DependsOn(path string) bool {
+	if _, ok := slices.BinarySearch(p.Files, path); ok {
+		return true
+	}
+	_, ok := slices.BinarySearch(p.Dirs, filepath.Dir(path))
+	return ok
+}
+
+// ListMainPackages returns the main packages matching pattern, sorted by
+// import path, using go list -deps to find the files each depends on. As the
+// dependencies of a package depend on the target, the packages are listed for
+// every platform, with its environment and build tags, and their inputs are
+// merged.
+func ListMainPackages(ctx context.Context, pattern string, platforms []types.Platform, options ...Option) ([]MainPackage, error) {
+	opts := newOptions(options)
+
+	var workFiles []string
+	work, err := Workspace(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	if work != "" {
+		work = filepath.Join(realPath(filepath.Dir(work)), filepath.Base(work))
+		workFiles = []string{work, work + ".sum"}
+	}
+
+	byImportPath := make(map[string]*MainPackage)
+	for _, platform := range platforms {
+		pkgs, err := listDeps(ctx, opts.forPlatform(platform), pattern, platform)
+		if err != nil {
+			return nil, err
+		}
+		byPath := make(map[string]*Package, len(pkgs))
+		for i := range pkgs {
+			byPath[pkgs[i].ImportPath] = &pkgs[i]
+		}
+		for _, pkg := range pkgs {
+			if pkg.DepOnly || pkg.Name != "main" {
+				continue
+			}
+			main, ok := byImportPath[pkg.ImportPath]
+			if !ok {
+				main = &MainPackage{Package: pkg, Files: slices.Clone(workFiles)}
+				byImportPath[pkg.ImportPath] = main
+			}
+			main.addInputs(&pkg)
+			for _, dep := range pkg.Deps {
+				if depPkg, ok := byPath[dep]; ok && !depPkg.Standard {
+					main.addInputs(depPkg)
+				}
+			}
+		}
+	}
+	if len(byImportPath) == 0 {
+		return nil, fmt.Errorf("%s matched no main packages", pattern)
+	}
+
+	mains := make([]MainPackage, 0, len(byImportPath))
+	for _, main := range byImportPath {
+		main.Dirs = sortedUnique(main.Dirs)
+		main.Files = sortedUnique(main.Files)
+		mains = append(mains, *main)
+	}
+	sort.Slice(mains, func(i, j int) bool {
+		return mains[i].ImportPath < mains[j].ImportPath
+	})
+	return mains, nil
+}
+
+// listDeps returns the packages matching pattern and their dependencies when
+// building for platform.
+func listDeps(ctx context.Context, opts options, pattern string, platform types.Platform) ([]Package, error) {
+	args := []string{"list", "-deps", "-json"}
+	if len(opts.buildTags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.buildTags, ","))
+	}
+	if opts.modFlag != "" {
+		args = append(args, "-mod", opts.modFlag)
+	}
+	out, err := runGoEnv(ctx, &opts, opts.goEnv(platform), append(args, "--", pattern)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []Package
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg Package
+		if err := dec.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list: %w", err)
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// addInputs adds the directory and other inputs of pkg. Paths are resolved
+// through symbolic links, as git reports them.
+func (p *MainPackage) addInputs(pkg *Package) {
+	dir := realPath(pkg.Dir)
+	p.Dirs = append(p.Dirs, dir)
+	for _, name := range pkg.EmbedFiles {
+		p.Files = append(p.Files, filepath.Join(dir, name))
+	}
+	if pkg.Module != nil && pkg.Module.GoMod != "" {
+		modDir := realPath(filepath.Dir(pkg.Module.GoMod))
+		p.Files = append(p.Files, filepath.Join(modDir, "go.mod"), filepath.Join(modDir, "go.sum"))
+	}
+}
+
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	return path
+}
+
+func sortedUnique(values []string) []string {
+	slices.Sort(values)
+	return slices.Compact(values)
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/testutil"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestListMainPackages(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"go.mod":                    "module example.com/mono\n\ngo 1.21\n",
+		"cmd/api/main.go":           "package main\n\nimport \"example.com/mono/lib\"\n\nfunc main() { lib.Run() }\n",
+		"cmd/web/main.go":           "package main\n\nimport _ \"embed\"\n\n//go:embed static/index.html\nvar index string\n\nfunc main() {}\n",
+		"cmd/web/static/index.html": "<html></html>\n",
+		"lib/lib.go":                "package lib\n\nfunc Run() {}\n",
+	})
+
+	pkgs, err := ListMainPackages(context.Background(), "./...", []types.Platform{types.DefaultPlatform}, WithDir(dir))
+	if err != nil {
+		t.Fatalf("ListMainPackages() error = %v", err)
+	}
+	if len(pkgs) != 2 || pkgs[0].ImportPath != "example.com/mono/cmd/api" || pkgs[1].ImportPath != "example.com/mono/cmd/web" {
+		t.Fatalf("ListMainPackages() = %+v, want cmd/api and cmd/web", pkgs)
+	}
+	api, web := pkgs[0], pkgs[1]
+
+	tests := []struct {
+		pkg  *MainPackage
+		path string
+		want bool
+	}{
+		{&api, "cmd/api/main.go", true},
+		{&api, "lib/lib.go", true},
+		{&api, "lib/lib_test.go", true},
+		{&api, "go.mod", true},
+		{&api, "go.sum", true},
+		{&api, "cmd/web/main.go", false},
+		{&api, "README.md", false},
+		{&web, "cmd/web/static/index.html", true},
+		{&web, "lib/lib.go", false},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, filepath.FromSlash(test.path))
+		if got := test.pkg.DependsOn(path); got != test.want {
+			t.Errorf("%s.DependsOn(%s) = %t, want %t", test.pkg.ImportPath, test.path, got, test.want)
+		}
+	}
+
+	_, err = ListMainPackages(context.Background(), "./lib", []types.Platform{types.DefaultPlatform}, WithDir(dir))
+	if err == nil || !strings.Contains(err.Error(), "matched no main packages") {
+		t.Fatalf("ListMainPackages() error = %v, want no main packages error", err)
+	}
+}
+
+func TestListMainPackagesTargets(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "")
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"go.mod":                "module example.com/mono\n\ngo 1.21\n",
+		"cmd/api/main.go":       "package main\n\nfunc main() {}\n",
+		"cmd/api/main_arm64.go": "package main\n\nimport _ \"example.com/mono/armlib\"\n",
+		"cmd/api/tagged.go":     "//go:build extra\n\npackage main\n\nimport _ \"example.com/mono/extralib\"\n",
+		"cmd/api/v6.go":         "//go:build arm && v6\n\npackage main\n\nimport _ \"example.com/mono/v6lib\"\n",
+		"armlib/armlib.go":      "package armlib\n",
+		"extralib/extralib.go":  "package extralib\n",
+		"v6lib/v6lib.go":        "package v6lib\n",
+	})
+	armv6 := types.ParsePlatform("linux/arm/v6")
+	pkgs, err := ListMainPackages(context.Background(), "./cmd/api",
+		[]types.Platform{types.ParsePlatform("linux/amd64"), types.ParsePlatform("linux/arm64"), armv6},
+		WithDir(dir),
+		WithBuildTags([]string{"extra"}),
+		WithPlatformOptions(map[types.Platform]PlatformOptions{armv6: {BuildTags: []string{"v6"}}}),
+	)
+	if err != nil {
+		t.Fatalf("ListMainPackages() error = %v", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("ListMainPackages() = %+v, want cmd/api", pkgs)
+	}
+	for _, lib := range []string{"armlib/armlib.go", "extralib/extralib.go", "v6lib/v6lib.go"} {
+		if !pkgs[0].DependsOn(filepath.Join(dir, filepath.FromSlash(lib))) {
+			t.Errorf("DependsOn(%s) = false, want true", lib)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// TinyGoBuilder compiles the main package with TinyGo, which produces much
+// smaller binaries at the cost of partial standard library support.
+type TinyGoBuilder struct {
+	opts options
+}
+
+// NewTinyGo returns a TinyGoBuilder, or an error if an option cannot be
+// translated to TinyGo. Of the ldflags, only -s and -w (as -no-debug) and -X
+// are supported; -mod and cgo are not supported. Trimpath is ignored.
+func NewTinyGo(options ...Option) (*TinyGoBuilder, error) {
+	opts := newOptions(options)
+	if opts.modFlag != "" {
+		return nil, errors.New("tinygo does not support -mod")
+	}
+	if opts.cgoEnabled {
+		return nil, errors.New("tinygo does not support enabling cgo")
+	}
+
+	if _, _, err := tinygoLDFlags(opts.ldflags); err != nil {
+		return nil, err
+	}
+	for platform, po := range opts.platformOptions {
+		if _, _, err := tinygoLDFlags(po.LDFlags); err != nil {
+			return nil, fmt.Errorf("%s: %w", platform, err)
+		}
+	}
+	return &TinyGoBuilder{opts: *opts}, nil
+}
+
+// tinygoLDFlags translates Go ldflags to TinyGo's -no-debug flag and the -X
+// flags it accepts.
+func tinygoLDFlags(ldflags string) (noDebug bool, xflags []string, err error) {
+	fields := strings.Fields(ldflags)
+	for i := 0; i < len(fields); i++ {
+		switch flag := fields[i]; {
+		case flag == "-s", flag == "-w":
+			noDebug = true
+		case flag == "-X":
+			if i+1 == len(fields) {
+				return false, nil, errors.New("invalid ldflags: -X requires a value")
+			}
+			i++
+			xflags = append(xflags, "-X", fields[i])
+		case strings.HasPrefix(flag, "-X="):
+			xflags = append(xflags, flag)
+		default:
+			return false, nil, fmt.Errorf("tinygo does not support ldflag %q (supported: -s, -w, -X)", flag)
+		}
+	}
+	return noDebug, xflags, nil
+}
+
+// CheckTinyGoPlatform returns an error if TinyGo cannot build for platform.
+func CheckTinyGoPlatform(platform types.Platform) error {
+	if platform.OS() != "linux" {
+		return fmt.Errorf("tinygo does not support %s", platform)
+	}
+	switch platform.Arch() {
+	case "386", "arm64":
+		if platform.Variant() != "" {
+			return fmt.Errorf("tinygo does not support %s", platform)
+		}
+	case "amd64":
+		if v, ok := variantNumber(platform); ok && v != 1 {
+			return fmt.Errorf("tinygo does not support %s: amd64 microarchitecture levels are not supported", platform)
+		}
+	case "arm":
+	default:
+		return fmt.Errorf("tinygo does not support %s", platform)
+	}
+	return nil
+}
+
+// Build compiles the main package for platform to outPath.
+func (b *TinyGoBuilder) Build(ctx context.Context, outPath string, platform types.Platform) error {
+	if err := CheckTinyGoPlatform(platform); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(b.opts.tinygoBin); err != nil {
+		return fmt.Errorf("tinygo: %w (see https://tinygo.org/getting-started/install)", err)
+	}
+
+	args, err := b.args(outPath, platform)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, b.opts.tinygoBin, args...)
+	cmd.Dir = b.opts.dir
+	cmd.Env = b.env(platform)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("tinygo: %w: %s", err, output.Bytes())
+	}
+	return nil
+}
+
+func (b *TinyGoBuilder) args(outPath string, platform types.Platform) ([]string, error) {
+	opts := b.opts.forPlatform(platform)
+	noDebug, xflags, err := tinygoLDFlags(opts.ldflags)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"build", "-o", outPath}
+	if noDebug {
+		args = append(args, "-no-debug")
+	}
+	if len(xflags) > 0 {
+		args = append(args, "-ldflags", strings.Join(xflags, " "))
+	}
+	if len(opts.buildTags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.buildTags, " "))
+	}
+	return append(args, opts.mainPath), nil
+}
+
+// env selects the TinyGo target for platform, which for Linux is chosen by
+// GOOS, GOARCH and GOARM.
+func (b *TinyGoBuilder) env(platform types.Platform) []string {
+	return envList(b.opts.forPlatform(platform).baseEnv(platform))
+}
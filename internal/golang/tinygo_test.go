@@ -0,0 +1,144 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestTinyGoArgs(t *testing.T) {
+	b, err := NewTinyGo(
+		WithLDFlags("-s -w -X main.version=1.2.3 -X=main.commit=abc"),
+		WithBuildTags([]string{"netgo", "edge"}),
+		WithMainPath("./cmd/app"),
+	)
+	if err != nil {
+		t.Fatalf("NewTinyGo() error = %v", err)
+	}
+
+	got, err := b.args("/tmp/app", types.ParsePlatform("linux/amd64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"build", "-o", "/tmp/app", "-no-debug",
+		"-ldflags", "-X main.version=1.2.3 -X=main.commit=abc",
+		"-tags", "netgo edge",
+		"./cmd/app",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+}
+
+func TestTinyGoPlatformOptions(t *testing.T) {
+	armv6 := types.ParsePlatform("linux/arm/v6")
+	b, err := NewTinyGo(
+		WithBuildTags([]string{"netgo"}),
+		WithPlatformOptions(map[types.Platform]PlatformOptions{
+			armv6: {LDFlags: "-X main.arch=armv6", BuildTags: []string{"slow"}},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewTinyGo() error = %v", err)
+	}
+
+	got, err := b.args("/tmp/app", armv6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"build", "-o", "/tmp/app", "-ldflags", "-X main.arch=armv6", "-tags", "netgo slow", "."}
+	if !slices.Equal(got, want) {
+		t.Fatalf("args = %q, want %q", got, want)
+	}
+
+	_, err = NewTinyGo(WithPlatformOptions(map[types.Platform]PlatformOptions{
+		armv6: {LDFlags: "-linkmode external"},
+	}))
+	if err == nil || !strings.Contains(err.Error(), `linux/arm/v6: tinygo does not support ldflag "-linkmode"`) {
+		t.Fatalf("NewTinyGo() error = %v, want per-platform ldflag error", err)
+	}
+}
+
+func TestNewTinyGoRejectsUnsupportedOptions(t *testing.T) {
+	tests := []struct {
+		option Option
+		want   string
+	}{
+		{WithLDFlags("-s -linkmode external"), `tinygo does not support ldflag "-linkmode"`},
+		{WithLDFlags("-X"), "-X requires a value"},
+		{WithModFlag("vendor"), "tinygo does not support -mod"},
+		{WithCGOEnabled(true), "tinygo does not support enabling cgo"},
+	}
+	for _, test := range tests {
+		_, err := NewTinyGo(test.option)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("NewTinyGo() error = %v, want %q", err, test.want)
+		}
+	}
+}
+
+func TestCheckTinyGoPlatform(t *testing.T) {
+	for _, platform := range []string{"linux/amd64", "linux/amd64/v1", "linux/arm64", "linux/386", "linux/arm/v6", "linux/arm/v7"} {
+		if err := CheckTinyGoPlatform(types.ParsePlatform(platform)); err != nil {
+			t.Fatalf("CheckTinyGoPlatform(%s) error = %v", platform, err)
+		}
+	}
+	err := CheckTinyGoPlatform(types.ParsePlatform("linux/amd64/v3"))
+	if err == nil || !strings.Contains(err.Error(), "microarchitecture levels are not supported") {
+		t.Fatalf("CheckTinyGoPlatform(linux/amd64/v3) error = %v", err)
+	}
+}
+
+func TestTinyGoBuild(t *testing.T) {
+	dir := t.TempDir()
+	tinygo := filepath.Join(dir, "tinygo")
+	script := "#!/bin/sh\nprintf '%s' \"$GOOS/$GOARCH/$GOARM $*\" > \"$3\"\n"
+	if err := os.WriteFile(tinygo, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewTinyGo(WithTinyGoBin(tinygo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "app")
+	if err := b.Build(context.Background(), out, types.ParsePlatform("linux/arm/v6")); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "linux/arm/6 build -o " + out + " -no-debug ."; string(got) != want {
+		t.Fatalf("tinygo invocation = %q, want %q", got, want)
+	}
+
+	b, err = NewTinyGo(WithTinyGoBin(filepath.Join(dir, "missing")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Build(context.Background(), out, types.ParsePlatform("linux/amd64"))
+	if err == nil || !strings.Contains(err.Error(), "tinygo.org") {
+		t.Fatalf("Build() error = %v, want install hint", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// SizeBudgetFail fails the run when an image exceeds a size budget.
+	SizeBudgetFail = "fail"
+	// SizeBudgetWarn logs a warning when an image exceeds a size budget and
+	// continues.
+	SizeBudgetWarn = "warn"
+)
+
+// Kinds of size budget.
+const (
+	SizeBudgetImage  = "image"
+	SizeBudgetBinary = "binary"
+)
+
+// SizeViolation describes an image that exceeds a size budget.
+type SizeViolation struct {
+	Platform string `json:"platform"`
+	// Budget is SizeBudgetImage, checked against the total compressed size of
+	// the image, or SizeBudgetBinary, checked against the compressed size of
+	// the application layer.
+	Budget string    `json:"budget"`
+	Limit  int64     `json:"limit"`
+	Size   ImageSize `json:"size"`
+}
+
+func (v SizeViolation) String() string {
+	size := v.Size.Total
+	if v.Budget == SizeBudgetBinary {
+		size = v.Size.App
+	}
+	return fmt.Sprintf("%s: %s size %s exceeds budget of %s (base %s, app %s)",
+		v.Platform, v.Budget, types.FormatSize(size), types.FormatSize(v.Limit),
+		types.FormatSize(v.Size.Base), types.FormatSize(v.Size.App))
+}
+
+// SizeBudgetError is returned when one or more images exceed a size budget.
+type SizeBudgetError struct {
+	Violations []SizeViolation
+}
+
+func (e *SizeBudgetError) Error() string {
+	var b strings.Builder
+	b.WriteString("size budget exceeded")
+	for _, v := range e.Violations {
+		b.WriteString("\n  ")
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+func validateSizeBudgets(opts *runOptions) error {
+	switch opts.sizeBudgetMode {
+	case SizeBudgetFail, SizeBudgetWarn:
+	default:
+		return fmt.Errorf("unsupported size budget mode %q (supported: %s, %s)", opts.sizeBudgetMode, SizeBudgetFail, SizeBudgetWarn)
+	}
+	if opts.maxImageSize < 0 || opts.maxBinarySize < 0 {
+		return errors.New("invalid size budget: must not be negative")
+	}
+	return nil
+}
+
+// setSizes sets the size breakdown of each built image. Layers not present
+// in the platform's base image are attributed to the application.
+func setSizes(images []ImageResult, bases map[types.Platform]v1.Image) error {
+	for i := range images {
+		var size ImageSize
+		for _, layer := range images[i].Layers {
+			size.Total += layer.Size
+		}
+		if base, ok := bases[types.ParsePlatform(images[i].Platform)]; ok {
+			manifest, err := base.Manifest()
+			if err != nil {
+				return err
+			}
+			for _, layer := range manifest.Layers {
+				size.Base += layer.Size
+			}
+		}
+		size.App = size.Total - size.Base
+		images[i].Size = size
+	}
+	return nil
+}
+
+// checkSizeBudgets returns the images exceeding the configured budgets. In
+// SizeBudgetFail mode, a *SizeBudgetError is also returned; otherwise a
+// warning is logged for each violation.
+func checkSizeBudgets(images []ImageResult, opts *runOptions) ([]SizeViolation, error) {
+	var violations []SizeViolation
+	for _, img := range images {
+		if opts.maxImageSize > 0 && img.Size.Total > opts.maxImageSize {
+			violations = append(violations, SizeViolation{
+				Platform: img.Platform,
+				Budget:   SizeBudgetImage,
+				Limit:    opts.maxImageSize,
+				Size:     img.Size,
+			})
+		}
+		if opts.maxBinarySize > 0 && img.Size.App > opts.maxBinarySize {
+			violations = append(violations, SizeViolation{
+				Platform: img.Platform,
+				Budget:   SizeBudgetBinary,
+				Limit:    opts.maxBinarySize,
+				Size:     img.Size,
+			})
+		}
+	}
+	if len(violations) == 0 {
+		return nil, nil
+	}
+	if opts.sizeBudgetMode == SizeBudgetFail {
+		return violations, &SizeBudgetError{Violations: violations}
+	}
+	for _, v := range violations {
+		opts.logger.Warnf("size budget exceeded: %s\n", v)
+	}
+	return violations, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestCheckSizeBudgets(t *testing.T) {
+	images := []ImageResult{
+		{Platform: "linux/amd64", Size: ImageSize{Base: 2_000_000, App: 9_000_000, Total: 11_000_000}},
+		{Platform: "linux/arm64", Size: ImageSize{Base: 2_000_000, App: 4_000_000, Total: 6_000_000}},
+	}
+	opts := defaultRunOptions()
+	opts.logger = NopLogger()
+	opts.maxImageSize = 10_000_000
+	opts.maxBinarySize = 5_000_000
+
+	violations, err := checkSizeBudgets(images, opts)
+	var budgetErr *SizeBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("checkSizeBudgets() error = %v, want SizeBudgetError", err)
+	}
+	if len(violations) != 2 || violations[0].Budget != SizeBudgetImage || violations[1].Budget != SizeBudgetBinary {
+		t.Fatalf("violations = %+v, want image and binary violations for linux/amd64", violations)
+	}
+	want := "linux/amd64: image size 11.0 MB exceeds budget of 10.0 MB (base 2.0 MB, app 9.0 MB)"
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %q, want it to contain %q", err, want)
+	}
+
+	opts.sizeBudgetMode = SizeBudgetWarn
+	violations, err = checkSizeBudgets(images, opts)
+	if err != nil || len(violations) != 2 {
+		t.Fatalf("checkSizeBudgets() = %v, %v, want 2 violations and no error", violations, err)
+	}
+}
+
+func TestRunFailsSizeBudget(t *testing.T) {
+	host := newTestRegistry(t)
+	base := imageWithPlatform(t, types.ParsePlatform("linux/amd64"))
+	writeTestImage(t, host+"/base:latest", base)
+	mainPath := writeTestMain(t)
+
+	options := []RunOption{
+		WithLogger(NopLogger()),
+		WithBase(host + "/base:latest"),
+		WithMainPath(mainPath),
+		WithRepository(host + "/app"),
+		WithDryRun(DryRunBuild),
+		WithMaxBinarySize(1),
+	}
+	res, err := Run(context.Background(), options...)
+	var budgetErr *SizeBudgetError
+	if !errors.As(err, &budgetErr) || len(budgetErr.Violations) != 1 {
+		t.Fatalf("Run() error = %v, want a SizeBudgetError", err)
+	}
+	if res == nil || len(res.Images) != 1 || res.Images[0].Size.App <= 0 || len(res.SizeViolations) != 1 {
+		t.Fatalf("Result = %+v, want the sizes of the failed build", res)
+	}
+
+	res, err = Run(context.Background(), append(options, WithSizeBudgetMode(SizeBudgetWarn))...)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	size := res.Images[0].Size
+	if size.Base != layersSize(t, base) || size.App <= 0 || size.Total != size.Base+size.App {
+		t.Fatalf("Result size = %+v, want base and app breakdown", size)
+	}
+	if len(res.SizeViolations) != 1 || res.SizeViolations[0].Budget != SizeBudgetBinary {
+		t.Fatalf("Result.SizeViolations = %+v, want binary violation", res.SizeViolations)
+	}
+}
+
+func TestRunRejectsUnsupportedSizeBudgetMode(t *testing.T) {
+	_, err := Run(context.Background(),
+		WithSizeBudgetMode("ignore"),
+		WithMainPath("/path/that/does/not/exist"),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unsupported size budget mode "ignore"`) {
+		t.Fatalf("Run() error = %v, want unsupported size budget mode error", err)
+	}
+}
+
+func layersSize(t *testing.T, img v1.Image) int64 {
+	t.Helper()
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size
+}
@@ -0,0 +1,317 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/golang"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// Supported compilers.
+const (
+	CompilerGo     = "go"
+	CompilerTinyGo = "tinygo"
+)
+
+// validateBuilder returns an error if the compiler, prebuilt binaries and
+// custom builder cannot be combined, and defaults the platforms to those of
+// the prebuilt binaries or to the default platform.
+func validateBuilder(opts *runOptions) error {
+	if opts.compiler != CompilerGo && opts.compiler != CompilerTinyGo {
+		return fmt.Errorf("unsupported compiler %q (supported: %s, %s)", opts.compiler, CompilerGo, CompilerTinyGo)
+	}
+	if len(opts.prebuilt) > 0 {
+		if opts.builder != nil {
+			return errors.New("cannot use prebuilt binaries with a custom builder")
+		}
+		if opts.compiler != CompilerGo {
+			return fmt.Errorf("cannot use prebuilt binaries with the %s compiler", opts.compiler)
+		}
+		if len(opts.platforms) == 0 {
+			for _, platform := range sortedPlatforms(prebuiltPlatforms(opts.prebuilt)) {
+				opts.platforms = append(opts.platforms, platform.String())
+			}
+		}
+	}
+	if opts.compiler == CompilerTinyGo {
+		if opts.builder != nil {
+			return fmt.Errorf("cannot use the %s compiler with a custom builder", opts.compiler)
+		}
+		if _, err := golang.NewTinyGo(goOptions(opts)...); err != nil {
+			return err
+		}
+	}
+	if len(opts.platforms) == 0 {
+		opts.platforms = []string{types.DefaultPlatform.String()}
+	}
+	return nil
+}
+
+// parsePlatformOptions validates the environment and per-platform overrides,
+// and sets opts.overrides to the overrides keyed by platform. Build overrides
+// and the environment require compiling the main package.
+func parsePlatformOptions(opts *runOptions) error {
+	if err := golang.CheckEnv(opts.env); err != nil {
+		return err
+	}
+	compiled := fromSource(opts)
+	if len(opts.env) > 0 && !compiled {
+		return errors.New("cannot set the build environment with prebuilt binaries or a custom builder")
+	}
+	opts.overrides = make(map[types.Platform]PlatformOptions, len(opts.platformOptions))
+	for key, po := range opts.platformOptions {
+		platform := types.ParsePlatform(key)
+		if !platform.IsSupported() {
+			return fmt.Errorf("platform options: unsupported platform %q", key)
+		}
+		if _, ok := opts.overrides[platform]; ok {
+			return fmt.Errorf("platform options: duplicate platform %s", platform)
+		}
+		if err := golang.CheckEnv(po.Env); err != nil {
+			return fmt.Errorf("platform options: %s: %w", platform, err)
+		}
+		if !compiled && (po.LDFlags != "" || len(po.BuildTags) > 0 || len(po.Env) > 0) {
+			return fmt.Errorf("platform options: %s: cannot override build options with prebuilt binaries or a custom builder", platform)
+		}
+		opts.overrides[platform] = po
+	}
+	return nil
+}
+
+// checkPlatforms returns an error if a platform cannot be built, or if a
+// platform is overridden but not built.
+func checkPlatforms(opts *runOptions, platforms []types.Platform) error {
+	for platform := range opts.overrides {
+		if !slices.Contains(platforms, platform) {
+			return fmt.Errorf("platform options: %s is not one of the platforms built", platform)
+		}
+	}
+	prebuilt := prebuiltPlatforms(opts.prebuilt)
+	for _, platform := range platforms {
+		if _, ok := prebuilt[platform]; len(prebuilt) > 0 && !ok {
+			return fmt.Errorf("no prebuilt binary for %s", platform)
+		}
+		if opts.compiler == CompilerTinyGo {
+			if err := golang.CheckTinyGoPlatform(platform); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fromSource reports whether the main package is compiled, rather than
+// provided by prebuilt binaries or a custom builder.
+func fromSource(opts *runOptions) bool {
+	return opts.builder == nil && len(opts.prebuilt) == 0
+}
+
+// setBuilder sets opts.builder to the builder that produces each platform's
+// binary, unless a custom builder was provided.
+func setBuilder(opts *runOptions) error {
+	switch {
+	case opts.builder != nil:
+	case len(opts.prebuilt) > 0:
+		builder, err := newPrebuiltBuilder(opts.prebuilt, opts.logger)
+		if err != nil {
+			return err
+		}
+		opts.builder = builder
+	case opts.compiler == CompilerTinyGo:
+		builder, err := golang.NewTinyGo(goOptions(opts)...)
+		if err != nil {
+			return err
+		}
+		opts.builder = builder
+	default:
+		opts.builder = golang.New(goOptions(opts)...)
+	}
+	return nil
+}
+
+// goOptions returns the compiler options shared by the Go and TinyGo
+// builders.
+func goOptions(opts *runOptions) []golang.Option {
+	goOptions := []golang.Option{
+		golang.WithBuildTags(opts.buildTags),
+		golang.WithCGOEnabled(opts.cgoEnabled),
+		golang.WithDir(opts.buildDir),
+		golang.WithEnv(opts.env),
+		golang.WithGoWork(goWorkPath(opts.workdir, opts.goWork)),
+		golang.WithTrimpath(opts.trimpathEnabled),
+	}
+	if len(opts.overrides) > 0 {
+		platformOptions := make(map[types.Platform]golang.PlatformOptions, len(opts.overrides))
+		for platform, po := range opts.overrides {
+			platformOptions[platform] = golang.PlatformOptions{
+				LDFlags:   po.LDFlags,
+				BuildTags: po.BuildTags,
+				Env:       po.Env,
+			}
+		}
+		goOptions = append(goOptions, golang.WithPlatformOptions(platformOptions))
+	}
+
+	if opts.ldflags != "" {
+		goOptions = append(goOptions, golang.WithLDFlags(opts.ldflags))
+	}
+	if opts.mainPath != "" {
+		goOptions = append(goOptions, golang.WithMainPath(opts.mainPath))
+	}
+	if opts.modFlag != "" {
+		goOptions = append(goOptions, golang.WithModFlag(opts.modFlag))
+	}
+	return goOptions
+}
+
+// goWorkPath returns goWork resolved against the workdir, as the go command
+// rejects a relative GOWORK and builds may run from a nested module root.
+func goWorkPath(workdir, goWork string) string {
+	if goWork == "" || goWork == "off" || filepath.IsAbs(goWork) {
+		return goWork
+	}
+	if abs, err := filepath.Abs(filepath.Join(workdir, goWork)); err == nil {
+		return abs
+	}
+	return goWork
+}
+
+// resolveMain returns the binary name for the main path, which is either a
+// local directory or file relative to the workdir, an import path, or an
+// import path at a version (path@version) as accepted by go install. When
+// building from source, local packages are built from the root of their
+// module, import paths are resolved with go list, and versioned packages are
+// built in a temporary module that the returned function removes.
+func resolveMain(ctx context.Context, opts *runOptions) (string, func(), error) {
+	noop := func() {}
+	if isLocalPath(opts.workdir, opts.mainPath) {
+		mainPath := opts.mainPath
+		if !filepath.IsAbs(mainPath) {
+			mainPath = filepath.Join(opts.workdir, mainPath)
+		}
+		binName, err := parseBinName(mainPath)
+		if err != nil || !fromSource(opts) {
+			return binName, noop, err
+		}
+		if err := setModuleRoot(opts, mainPath); err != nil {
+			return "", noop, err
+		}
+		return binName, noop, golang.CheckModFlag(ctx, goOptions(opts)...)
+	}
+
+	importPath, version, versioned := strings.Cut(opts.mainPath, "@")
+	if importPath == "" || (versioned && version == "") {
+		return "", noop, fmt.Errorf("invalid main package %q", opts.mainPath)
+	}
+	binName := golang.BinName(importPath)
+	if !fromSource(opts) {
+		return binName, noop, nil
+	}
+	if !versioned {
+		opts.buildDir = opts.workdir
+		if err := golang.CheckModFlag(ctx, goOptions(opts)...); err != nil {
+			return "", noop, err
+		}
+		if _, err := golang.ResolveMain(ctx, importPath, goOptions(opts)...); err != nil {
+			return "", noop, err
+		}
+		return binName, noop, nil
+	}
+
+	if opts.modFlag != "" {
+		return "", noop, fmt.Errorf("cannot use -mod with %s", opts.mainPath)
+	}
+	opts.logger.Debugf("Resolving %s in a temporary module\n", opts.mainPath)
+	dir, err := golang.NewTempModule(ctx, importPath, version)
+	if err != nil {
+		return "", noop, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	opts.mainPath = importPath
+	opts.buildDir = dir
+	opts.goWork = "off"
+	if _, err := golang.ResolveMain(ctx, importPath, goOptions(opts)...); err != nil {
+		cleanup()
+		return "", noop, err
+	}
+	return binName, cleanup, nil
+}
+
+// setModuleRoot sets the build directory to the root of the module that
+// contains the local main package at mainPath, with the main path relative to
+// it, so that packages in nested modules can be built from outside of them.
+// If there is no such module, the build runs in the workdir.
+func setModuleRoot(opts *runOptions, mainPath string) error {
+	abs, err := filepath.Abs(mainPath)
+	if err != nil {
+		return err
+	}
+	dir := abs
+	if stat, err := os.Stat(abs); err == nil && !stat.IsDir() {
+		dir = filepath.Dir(abs)
+	}
+	root, ok := golang.ModuleRoot(dir)
+	if !ok {
+		opts.buildDir = opts.workdir
+		return nil
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return err
+	}
+	opts.buildDir = root
+	opts.mainPath = "./" + filepath.ToSlash(rel)
+	if rel == "." {
+		opts.mainPath = "."
+	}
+	opts.logger.Debugf("Building %s in module %s\n", opts.mainPath, root)
+	return nil
+}
+
+// isLocalPath reports whether the main path refers to the file system, relative
+// to workdir, rather than to an import path.
+func isLocalPath(workdir, mainPath string) bool {
+	if mainPath == "." || mainPath == ".." || filepath.IsAbs(mainPath) ||
+		strings.HasPrefix(mainPath, "./") || strings.HasPrefix(mainPath, "../") ||
+		strings.HasPrefix(mainPath, "."+string(filepath.Separator)) ||
+		strings.HasPrefix(mainPath, ".."+string(filepath.Separator)) {
+		return true
+	}
+	if strings.Contains(mainPath, "@") {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(workdir, mainPath))
+	return err == nil
+}
+
+func parseBinName(mainPath string) (string, error) {
+	mainPath, err := filepath.Abs(mainPath)
+	if err != nil {
+		return "", err
+	}
+	stat, err := os.Stat(mainPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(stat.Name(), filepath.Ext(stat.Name())), nil
+}
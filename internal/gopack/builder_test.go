@@ -0,0 +1,245 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/testutil"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestRunTinyGo(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	mainPath := writeTestMain(t)
+
+	bin := t.TempDir()
+	script := "#!/bin/sh\nprintf tinygo > \"$3\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "tinygo"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath(mainPath),
+		WithRepository(host+"/app"),
+		WithDryRun(DryRunBuild),
+		WithCompiler(CompilerTinyGo),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Images) != 1 || res.Images[0].Digest == "" {
+		t.Fatalf("Result.Images = %+v, want one built image", res.Images)
+	}
+}
+
+func TestRunRejectsUnsupportedCompilerCombinations(t *testing.T) {
+	tests := []struct {
+		options []RunOption
+		want    string
+	}{
+		{[]RunOption{WithCompiler("gccgo")}, `unsupported compiler "gccgo"`},
+		{[]RunOption{WithCompiler(CompilerTinyGo), WithPlatforms([]string{"linux/amd64/v3"})}, "tinygo does not support linux/amd64/v3"},
+		{[]RunOption{WithCompiler(CompilerTinyGo), WithLDFlags("-linkmode external")}, `tinygo does not support ldflag "-linkmode"`},
+		{[]RunOption{WithCompiler(CompilerTinyGo), WithPrebuilt(map[string]string{"linux/amd64": "app"})}, "cannot use prebuilt binaries with the tinygo compiler"},
+		{[]RunOption{WithCompiler(CompilerTinyGo), WithBuilder(&fakeBuilder{})}, "cannot use the tinygo compiler with a custom builder"},
+	}
+	for _, test := range tests {
+		options := append(test.options, WithMainPath("/path/that/does/not/exist"))
+		_, err := Run(context.Background(), options...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("Run() error = %v, want %q", err, test.want)
+		}
+	}
+}
+
+func TestRunImportPath(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	writeTestMain(t)
+
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath("example.com/app"),
+		WithDryRun(DryRunBuild),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Repository != "app" || res.Images[0].Digest == "" {
+		t.Fatalf("Result = %+v, want image built for repository app", res)
+	}
+}
+
+func TestRunModuleVersion(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	t.Chdir(t.TempDir())
+	testutil.UseModuleProxy(t, "example.com/tool/v2", "v2.1.0", map[string]string{
+		"go.mod":     "module example.com/tool/v2\n\ngo 1.21\n",
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"lib/lib.go": "package lib\n",
+	})
+
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath("example.com/tool/v2@v2.1.0"),
+		WithRepository(host+"/tool"),
+		WithDryRun(DryRunBuild),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Images[0].Digest == "" {
+		t.Fatalf("Result.Images = %+v, want built image", res.Images)
+	}
+
+	_, err = Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath("example.com/tool/v2/lib@v2.1.0"),
+		WithDryRun(DryRunBuild),
+	)
+	if err == nil || !strings.Contains(err.Error(), "is not a main package") {
+		t.Fatalf("Run() error = %v, want not a main package error", err)
+	}
+}
+
+func TestRunNestedModule(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"services/api/go.mod":          "module example.com/api\n\ngo 1.21\n",
+		"services/api/cmd/api/main.go": "package main\n\nfunc main() {}\n",
+	})
+
+	t.Chdir(root)
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath("./services/api/cmd/api"),
+		WithDryRun(DryRunBuild),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Repository != "api" || res.Images[0].Digest == "" {
+		t.Fatalf("Result = %+v, want image built for repository api", res)
+	}
+
+	t.Chdir(t.TempDir())
+	res, err = Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithWorkdir(root),
+		WithMainPath("services/api/cmd/api"),
+		WithDryRun(DryRunBuild),
+	)
+	if err != nil {
+		t.Fatalf("Run() with workdir error = %v", err)
+	}
+	if res.Repository != "api" || res.Images[0].Digest == "" {
+		t.Fatalf("Result = %+v, want image built for repository api", res)
+	}
+}
+
+func TestRunWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	t.Setenv("GOFLAGS", "")
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		"go.work":     "go 1.21\n\nuse (\n\t./app\n\t./lib\n)\n",
+		"app/go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"app/main.go": "package main\n\nimport \"example.com/lib\"\n\nfunc main() { lib.Run() }\n",
+		"lib/go.mod":  "module example.com/lib\n\ngo 1.21\n",
+		"lib/lib.go":  "package lib\n\nfunc Run() {}\n",
+	})
+
+	run := func(options ...RunOption) (*Result, error) {
+		return Run(context.Background(), append([]RunOption{
+			WithLogger(NopLogger()),
+			WithBase(host + "/base:latest"),
+			WithWorkdir(root),
+			WithDryRun(DryRunBuild),
+		}, options...)...)
+	}
+
+	for _, mainPath := range []string{"./app", "example.com/app"} {
+		res, err := run(WithMainPath(mainPath), WithModFlag("readonly"))
+		if err != nil {
+			t.Fatalf("Run(%q) error = %v", mainPath, err)
+		}
+		if res.Repository != "app" || res.Images[0].Digest == "" {
+			t.Fatalf("Result = %+v, want image built for repository app", res)
+		}
+	}
+
+	if _, err := run(WithMainPath("./app"), WithGoWork("go.work")); err != nil {
+		t.Fatalf("Run() with relative GOWORK error = %v", err)
+	}
+
+	_, err := run(WithMainPath("./app"), WithModFlag("mod"))
+	if err == nil || !strings.Contains(err.Error(), "cannot use -mod=mod with workspace") {
+		t.Fatalf("Run() error = %v, want -mod=mod workspace error", err)
+	}
+	_, err = run(WithMainPath("./app"), WithModFlag("vendor"))
+	if err == nil || !strings.Contains(err.Error(), "run go work vendor") {
+		t.Fatalf("Run() error = %v, want unvendored workspace error", err)
+	}
+	_, err = run(WithMainPath("./app"), WithGoWork("off"))
+	if err == nil {
+		t.Fatal("Run() with GOWORK=off error = nil, want missing module error")
+	}
+}
+
+func TestResolveMainBinName(t *testing.T) {
+	tests := map[string]string{
+		"golang.org/x/tools/cmd/stringer@v0.20.0": "stringer",
+		"github.com/org/repo/cmd/tool":            "tool",
+		"example.com/tool/v2@latest":              "tool",
+	}
+	for mainPath, want := range tests {
+		opts := defaultRunOptions()
+		opts.mainPath = mainPath
+		opts.builder = &fakeBuilder{}
+		binName, cleanup, err := resolveMain(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("resolveMain(%q) error = %v", mainPath, err)
+		}
+		cleanup()
+		if binName != want {
+			t.Fatalf("resolveMain(%q) = %q, want %q", mainPath, binName, want)
+		}
+	}
+
+	opts := defaultRunOptions()
+	opts.mainPath = "example.com/tool@"
+	if _, _, err := resolveMain(context.Background(), opts); err == nil {
+		t.Fatal("resolveMain() error = nil, want invalid main package error")
+	}
+}
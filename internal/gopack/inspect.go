@@ -0,0 +1,209 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Inspection describes an image or index read by Inspect.
+type Inspection struct {
+	// Reference is the inspected reference or archive path.
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	// Images contains each image of an index, in index order, or the single
+	// inspected image.
+	Images []ImageInspection `json:"images"`
+}
+
+// ImageInspection describes a single image manifest and its config.
+type ImageInspection struct {
+	Platform  string              `json:"platform"`
+	Digest    string              `json:"digest"`
+	MediaType string              `json:"mediaType"`
+	Config    ConfigInspection    `json:"config"`
+	Layers    []LayerResult       `json:"layers"`
+	History   []HistoryInspection `json:"history,omitempty"`
+}
+
+// ConfigInspection contains the runtime configuration of an image.
+type ConfigInspection struct {
+	Created    time.Time         `json:"created,omitzero"`
+	Entrypoint []string          `json:"entrypoint,omitempty"`
+	Cmd        []string          `json:"cmd,omitempty"`
+	User       string            `json:"user,omitempty"`
+	WorkingDir string            `json:"workingDir,omitempty"`
+	Env        []string          `json:"env,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// HistoryInspection is a single entry of an image's history.
+type HistoryInspection struct {
+	Created    time.Time `json:"created,omitzero"`
+	CreatedBy  string    `json:"createdBy,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	EmptyLayer bool      `json:"emptyLayer,omitempty"`
+}
+
+// Inspect reads the image or index at target, which is either a remote
+// reference or an OCI archive written by Run as "oci:<path>". Only the
+// registry and logger options apply.
+func Inspect(ctx context.Context, target string, options ...RunOption) (*Inspection, error) {
+	opts := defaultRunOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	if path, ok := strings.CutPrefix(target, ociOutputPrefix); ok {
+		if path == "" {
+			return nil, fmt.Errorf("invalid archive %q: missing path", target)
+		}
+		archive, err := oci.OpenArchive(path)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		return inspectManifest(path, archive.Index)
+	}
+
+	ref, err := name.ParseReference(target)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse reference: %w", err)
+	}
+	opts.logger.Debugf("Fetching manifest for %s\n", ref)
+	desc, err := oci.Get(ctx, ref, opts.registryOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", ref, err)
+	}
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		return inspectManifest(ref.String(), index)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	return inspectManifest(ref.String(), img)
+}
+
+func inspectManifest(reference string, m manifest) (*Inspection, error) {
+	digest, err := m.Digest()
+	if err != nil {
+		return nil, err
+	}
+	mt, err := m.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	out := &Inspection{
+		Reference: reference,
+		Digest:    digest.String(),
+		MediaType: string(mt),
+	}
+
+	switch m := m.(type) {
+	case v1.Image:
+		img, err := inspectImage(nil, m)
+		if err != nil {
+			return nil, err
+		}
+		out.Images = []ImageInspection{img}
+	case v1.ImageIndex:
+		indexManifest, err := m.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range indexManifest.Manifests {
+			if !desc.MediaType.IsImage() {
+				continue
+			}
+			child, err := m.Image(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			img, err := inspectImage(desc.Platform, child)
+			if err != nil {
+				return nil, err
+			}
+			out.Images = append(out.Images, img)
+		}
+	}
+	return out, nil
+}
+
+// inspectImage describes img. If platform is nil, it is read from the image
+// config.
+func inspectImage(platform *v1.Platform, img v1.Image) (ImageInspection, error) {
+	config, err := img.ConfigFile()
+	if err != nil {
+		return ImageInspection{}, err
+	}
+	if platform == nil {
+		platform = config.Platform()
+	}
+	res, err := describeImage(platformOf(platform), img)
+	if err != nil {
+		return ImageInspection{}, err
+	}
+
+	out := ImageInspection{
+		Platform:  res.Platform,
+		Digest:    res.Digest,
+		MediaType: res.MediaType,
+		Layers:    res.Layers,
+		Config: ConfigInspection{
+			Created:    config.Created.Time,
+			Entrypoint: config.Config.Entrypoint,
+			Cmd:        config.Config.Cmd,
+			User:       config.Config.User,
+			WorkingDir: config.Config.WorkingDir,
+			Env:        config.Config.Env,
+			Labels:     config.Config.Labels,
+		},
+	}
+	for _, h := range config.History {
+		out.History = append(out.History, HistoryInspection{
+			Created:    h.Created.Time,
+			CreatedBy:  h.CreatedBy,
+			Comment:    h.Comment,
+			EmptyLayer: h.EmptyLayer,
+		})
+	}
+	return out, nil
+}
+
+func platformOf(p *v1.Platform) types.Platform {
+	if p == nil {
+		return types.ParsePlatform("unknown/unknown")
+	}
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return types.ParsePlatform(s)
+}
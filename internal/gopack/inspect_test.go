@@ -0,0 +1,108 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+)
+
+func TestInspectRemoteImage(t *testing.T) {
+	host := newTestRegistry(t)
+	img := configuredImage(t, types.ParsePlatform("linux/arm64"))
+	writeTestImage(t, host+"/app:v1", img)
+
+	res, err := Inspect(context.Background(), host+"/app:v1", WithLogger(NopLogger()))
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Digest != digest.String() || len(res.Images) != 1 {
+		t.Fatalf("Inspect() = %+v, want single image %s", res, digest)
+	}
+	got := res.Images[0]
+	if got.Platform != "linux/arm64" {
+		t.Fatalf("Platform = %q, want linux/arm64", got.Platform)
+	}
+	if len(got.Config.Entrypoint) != 1 || got.Config.Entrypoint[0] != "/app" {
+		t.Fatalf("Entrypoint = %v, want [/app]", got.Config.Entrypoint)
+	}
+	if got.Config.User != "nonroot" || got.Config.Labels["team"] != "platform" {
+		t.Fatalf("Config = %+v, want user and labels", got.Config)
+	}
+	if len(got.Layers) != 1 || got.Layers[0].Size == 0 {
+		t.Fatalf("Layers = %+v, want 1 non-empty layer", got.Layers)
+	}
+	if len(got.History) != 1 || got.History[0].CreatedBy != "gopack" {
+		t.Fatalf("History = %+v, want gopack entry", got.History)
+	}
+}
+
+func TestInspectArchive(t *testing.T) {
+	imgs := map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/amd64"): configuredImage(t, types.ParsePlatform("linux/amd64")),
+		types.ParsePlatform("linux/arm64"): configuredImage(t, types.ParsePlatform("linux/arm64")),
+	}
+	index := makeImageIndex(imgs, "")
+	path := filepath.Join(t.TempDir(), "image.tar")
+	if err := oci.WriteArchive(path, index); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Inspect(context.Background(), "oci:"+path)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	digest, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Reference != path || res.Digest != digest.String() {
+		t.Fatalf("Inspect() = %s %s, want %s %s", res.Reference, res.Digest, path, digest)
+	}
+	if len(res.Images) != 2 || res.Images[0].Platform != "linux/amd64" || res.Images[1].Platform != "linux/arm64" {
+		t.Fatalf("Inspect() images = %+v, want amd64 and arm64", res.Images)
+	}
+}
+
+func configuredImage(t *testing.T, platform types.Platform) v1.Image {
+	t.Helper()
+
+	img := imageWithPlatform(t, platform)
+	config, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = config.DeepCopy()
+	config.Config.Entrypoint = []string{"/app"}
+	config.Config.User = "nonroot"
+	config.Config.Labels = map[string]string{"team": "platform"}
+	config.History = []v1.History{{CreatedBy: "gopack"}}
+	img, err = mutate.ConfigFile(img, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
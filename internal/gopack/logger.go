@@ -15,8 +15,14 @@
 package gopack
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/ryanfowler/gopack/internal/types"
 
@@ -25,32 +31,126 @@ import (
 
 var (
 	_ types.Logger = (*defaultLogger)(nil)
+	_ types.Logger = (*slogLogger)(nil)
 	_ types.Logger = nopLogger{}
 )
 
 type defaultLogger struct {
+	w          io.Writer
+	level      types.Level
 	isTerminal bool
+
+	mu      sync.Mutex
+	display *ttyDisplay
 }
 
 // StdErrLogger returns a new Logger that logs to stderr.
 func StdErrLogger() types.Logger {
+	return NewTextLogger(os.Stderr, types.LevelInfo)
+}
+
+// NewTextLogger returns a Logger that writes human-readable messages at or
+// above the provided level to w.
+func NewTextLogger(w io.Writer, level types.Level) types.Logger {
+	var isTerminal bool
+	if f, ok := w.(*os.File); ok {
+		isTerminal = term.IsTerminal(int(f.Fd()))
+	}
 	return &defaultLogger{
-		isTerminal: term.IsTerminal(int(os.Stderr.Fd())),
+		w:          w,
+		level:      level,
+		isTerminal: isTerminal,
 	}
 }
 
 func (l *defaultLogger) Printf(format string, a ...any) {
-	fmt.Fprintf(os.Stderr, format, a...)
+	l.logf(types.LevelInfo, "", format, a...)
 }
 
 func (l *defaultLogger) Println(a ...any) {
-	fmt.Fprintln(os.Stderr, a...)
+	if l.level <= types.LevelInfo {
+		l.write(fmt.Sprintln(a...))
+	}
+}
+
+func (l *defaultLogger) Debugf(format string, a ...any) {
+	l.logf(types.LevelDebug, "", format, a...)
+}
+
+func (l *defaultLogger) Warnf(format string, a ...any) {
+	l.logf(types.LevelWarn, "warning: ", format, a...)
+}
+
+func (l *defaultLogger) Errorf(format string, a ...any) {
+	l.logf(types.LevelError, "error: ", format, a...)
+}
+
+func (l *defaultLogger) logf(level types.Level, prefix, format string, a ...any) {
+	if level >= l.level {
+		l.write(prefix + fmt.Sprintf(format, a...))
+	}
+}
+
+// write writes msg, printing it above the progress display if one is active.
+func (l *defaultLogger) write(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.display != nil {
+		l.display.clear()
+		defer l.display.draw()
+	}
+	io.WriteString(l.w, msg)
+}
+
+type slogLogger struct {
+	h slog.Handler
+}
+
+// NewJSONLogger returns a Logger that writes messages at or above the
+// provided level to w as JSON lines.
+func NewJSONLogger(w io.Writer, level types.Level) types.Logger {
+	return SlogLogger(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level: slog.Level(level),
+	}))
+}
+
+// SlogLogger returns a Logger that sends every message to h.
+func SlogLogger(h slog.Handler) types.Logger {
+	return &slogLogger{h: h}
+}
+
+func (l *slogLogger) Printf(format string, a ...any) {
+	l.log(types.LevelInfo, fmt.Sprintf(format, a...))
+}
+
+func (l *slogLogger) Println(a ...any) {
+	l.log(types.LevelInfo, fmt.Sprintln(a...))
 }
 
-func (l *defaultLogger) RePrintf(format string, a ...any) {
-	if l.isTerminal {
-		fmt.Fprintf(os.Stderr, "\033[2K\r"+format, a...)
+func (l *slogLogger) Debugf(format string, a ...any) {
+	l.log(types.LevelDebug, fmt.Sprintf(format, a...))
+}
+
+func (l *slogLogger) Warnf(format string, a ...any) {
+	l.log(types.LevelWarn, fmt.Sprintf(format, a...))
+}
+
+func (l *slogLogger) Errorf(format string, a ...any) {
+	l.log(types.LevelError, fmt.Sprintf(format, a...))
+}
+
+func (l *slogLogger) log(level types.Level, msg string) {
+	ctx := context.Background()
+	if !l.h.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	// Messages are written with trailing newlines for the text logger, which
+	// are meaningless to a structured handler.
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
 	}
+	_ = l.h.Handle(ctx, slog.NewRecord(time.Now(), slog.Level(level), msg, 0))
 }
 
 type nopLogger struct{}
@@ -62,4 +162,8 @@ func (l nopLogger) Printf(format string, a ...any) {}
 
 func (l nopLogger) Println(a ...any) {}
 
-func (l nopLogger) RePrintf(format string, a ...any) {}
+func (l nopLogger) Debugf(format string, a ...any) {}
+
+func (l nopLogger) Warnf(format string, a ...any) {}
+
+func (l nopLogger) Errorf(format string, a ...any) {}
@@ -0,0 +1,67 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestTextLoggerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewTextLogger(&buf, types.LevelWarn)
+	l.Debugf("debug\n")
+	l.Printf("info\n")
+	l.Warnf("careful\n")
+	l.Errorf("failed\n")
+
+	if got, want := buf.String(), "warning: careful\nerror: failed\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewJSONLogger(&buf, types.LevelDebug)
+	l.Debugf("Resolved base %s\n", "example.com/base")
+	l.Println()
+	l.Errorf("failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	var entry struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != "DEBUG" || entry.Msg != "Resolved base example.com/base" {
+		t.Fatalf("entry = %+v, want trimmed debug message", entry)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != "ERROR" {
+		t.Fatalf("entry level = %q, want ERROR", entry.Level)
+	}
+}
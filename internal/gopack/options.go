@@ -16,10 +16,22 @@ package gopack
 
 import (
 	"compress/gzip"
+	"maps"
+	"os"
 	"runtime"
+	"time"
 
 	"github.com/ryanfowler/gopack/internal/oci"
 	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// Environment variables holding credentials for the destination registry.
+const (
+	EnvRegistryUsername = "GOPACK_REGISTRY_USERNAME"
+	EnvRegistryPassword = "GOPACK_REGISTRY_PASSWORD"
 )
 
 type RunOption func(*runOptions)
@@ -30,12 +42,36 @@ func WithConcurrency(v int) RunOption {
 	}
 }
 
+// WithPushJobs sets the number of images uploaded concurrently, each as soon
+// as it is built, independently of the number of concurrent builds.
+func WithPushJobs(v int) RunOption {
+	return func(ro *runOptions) {
+		ro.pushJobs = v
+	}
+}
+
 func WithLogger(v types.Logger) RunOption {
 	return func(ro *runOptions) {
 		ro.logger = v
 	}
 }
 
+// WithBuildTags sets the build tags passed to the compiler.
+func WithBuildTags(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.buildTags = v
+	}
+}
+
+// WithCompiler sets the compiler used to build the main package: CompilerGo
+// (the default) or CompilerTinyGo. TinyGo only supports the -s, -w and -X
+// ldflags, and does not support cgo or -mod.
+func WithCompiler(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.compiler = v
+	}
+}
+
 func WithCGOEnabled(v bool) RunOption {
 	return func(ro *runOptions) {
 		ro.cgoEnabled = v
@@ -48,6 +84,14 @@ func WithLDFlags(v string) RunOption {
 	}
 }
 
+// WithEnv sets additional environment variables, as KEY=VALUE, for the
+// compiler.
+func WithEnv(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.env = v
+	}
+}
+
 func WithMainPath(v string) RunOption {
 	return func(ro *runOptions) {
 		ro.mainPath = v
@@ -60,6 +104,62 @@ func WithModFlag(v string) RunOption {
 	}
 }
 
+// WithWorkdir sets the directory that go and git commands run in and that a
+// local main path is relative to. It defaults to the current directory.
+func WithWorkdir(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.workdir = v
+	}
+}
+
+// WithGoWork sets GOWORK for the go commands run: the path of a go.work file,
+// relative to the workdir if not absolute, or "off" to disable workspaces. By
+// default, GOWORK is inherited from the environment.
+func WithGoWork(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.goWork = v
+	}
+}
+
+// WithBuilder sets the builder that produces each platform's binary instead
+// of compiling the main package with go build.
+func WithBuilder(v types.Builder) RunOption {
+	return func(ro *runOptions) {
+		ro.builder = v
+	}
+}
+
+// WithPrebuilt packages binaries built elsewhere instead of compiling them.
+// The keys are platforms (e.g. "linux/arm64") and the values are paths to
+// Linux executables for them, which are validated before packaging. Unless
+// set with WithPlatforms, the platforms are those of the binaries.
+func WithPrebuilt(v map[string]string) RunOption {
+	return func(ro *runOptions) {
+		ro.prebuilt = v
+	}
+}
+
+// PlatformOptions overrides options for a single platform. Empty fields keep
+// the shared values.
+type PlatformOptions struct {
+	// Base replaces the base image.
+	Base string
+	// LDFlags replaces the ldflags.
+	LDFlags string
+	// BuildTags are added to the build tags.
+	BuildTags []string
+	// Env is added to the compiler's environment, as KEY=VALUE.
+	Env []string
+}
+
+// WithPlatformOptions sets per-platform overrides, keyed by platform (e.g.
+// "linux/arm/v6"). Every platform must be one of those built.
+func WithPlatformOptions(v map[string]PlatformOptions) RunOption {
+	return func(ro *runOptions) {
+		ro.platformOptions = v
+	}
+}
+
 func WithTrimpath(v bool) RunOption {
 	return func(ro *runOptions) {
 		ro.trimpathEnabled = v
@@ -78,6 +178,23 @@ func WithCompressionLevel(v int) RunOption {
 	}
 }
 
+// WithLayerCompression sets the compression of the application layer: "gzip"
+// (the default), "zstd" or "none". Images with zstd or uncompressed layers
+// are written as OCI manifests.
+func WithLayerCompression(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.layerCompression = v
+	}
+}
+
+// WithUPX packs each binary with UPX before layering it, if a upx executable
+// is found in PATH.
+func WithUPX(v bool) RunOption {
+	return func(ro *runOptions) {
+		ro.upx = v
+	}
+}
+
 func WithDaemon(v string) RunOption {
 	return func(ro *runOptions) {
 		ro.daemon = v
@@ -108,9 +225,27 @@ func WithPlatforms(v []string) RunOption {
 	}
 }
 
+// WithPlatformTags sets whether, when pushing to registries, each platform's
+// image is also tagged as <tag>-<os>-<arch>[-<variant>] for every tag, for
+// clients that cannot resolve an image index.
+func WithPlatformTags(v bool) RunOption {
+	return func(ro *runOptions) {
+		ro.platformTags = v
+	}
+}
+
 func WithRepository(v string) RunOption {
 	return func(ro *runOptions) {
-		ro.repository = v
+		ro.repositories = []string{v}
+	}
+}
+
+// WithRepositories sets every repository the image is pushed or loaded to.
+// Blobs are uploaded once per registry and tags are applied in each
+// repository.
+func WithRepositories(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.repositories = v
 	}
 }
 
@@ -120,49 +255,286 @@ func WithTags(v []string) RunOption {
 	}
 }
 
+// WithDryRun resolves and plans the run without writing to any registry,
+// daemon or archive. The mode is DryRunPlan or DryRunBuild.
+func WithDryRun(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.dryRun = v
+	}
+}
+
+// WithImmutableTags refuses to move tags matching any of the path.Match
+// patterns (e.g. "*" or "v*") to a different digest when pushing to a
+// registry.
+func WithImmutableTags(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.immutableTags = v
+	}
+}
+
+// WithSkipIfExists skips pushing to a repository that already contains the
+// image's digest, leaving its tags, including platform tags, unchanged.
+func WithSkipIfExists(v bool) RunOption {
+	return func(ro *runOptions) {
+		ro.skipIfExists = v
+	}
+}
+
+// WithSince only builds the main packages matched by RunPackages that are
+// affected by the changes in the working tree since the merge base of the git
+// ref and HEAD.
+func WithSince(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.since = v
+	}
+}
+
+// WithTagStrategy derives additional tags from the git checkout. The only
+// supported strategy is TagStrategySemver.
+func WithTagStrategy(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.tagStrategy = v
+	}
+}
+
+// WithTagTemplates adds tags rendered from Go templates. Templates can use
+// {{.Commit}}, {{.ShortCommit}}, {{.Branch}} and {{.Date}}.
+func WithTagTemplates(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.tagTemplates = v
+	}
+}
+
+// WithMaxImageSize sets a budget, in bytes, for the total compressed size of
+// each image. Zero disables the check.
+func WithMaxImageSize(v int64) RunOption {
+	return func(ro *runOptions) {
+		ro.maxImageSize = v
+	}
+}
+
+// WithMaxBinarySize sets a budget, in bytes, for the compressed size of the
+// layers added on top of the base image. Zero disables the check.
+func WithMaxBinarySize(v int64) RunOption {
+	return func(ro *runOptions) {
+		ro.maxBinarySize = v
+	}
+}
+
+// WithSizeBudgetMode sets whether exceeding a size budget fails the run
+// (SizeBudgetFail, the default) or only logs a warning (SizeBudgetWarn).
+func WithSizeBudgetMode(v string) RunOption {
+	return func(ro *runOptions) {
+		ro.sizeBudgetMode = v
+	}
+}
+
+func WithRetries(v int) RunOption {
+	return func(ro *runOptions) {
+		ro.retries = v
+	}
+}
+
+func WithRetryBackoff(v time.Duration) RunOption {
+	return func(ro *runOptions) {
+		ro.retryBackoff = v
+	}
+}
+
+func WithRegistryTimeout(v time.Duration) RunOption {
+	return func(ro *runOptions) {
+		ro.registryTimeout = v
+	}
+}
+
+func WithInsecureRegistries(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.insecureRegistries = v
+	}
+}
+
+func WithCAFiles(v []string) RunOption {
+	return func(ro *runOptions) {
+		ro.caFiles = v
+	}
+}
+
+func WithRegistryMirrors(v map[string]string) RunOption {
+	return func(ro *runOptions) {
+		ro.registryMirrors = v
+	}
+}
+
+// WithCredentials sets explicit credentials by registry. Credentials from the
+// GOPACK_REGISTRY_USERNAME and GOPACK_REGISTRY_PASSWORD environment variables
+// are used for the destination registry if it has none.
+func WithCredentials(v map[string]types.Credential) RunOption {
+	return func(ro *runOptions) {
+		ro.credentials = v
+	}
+}
+
+func WithCreateRepository(v bool) RunOption {
+	return func(ro *runOptions) {
+		ro.createRepository = v
+	}
+}
+
 type runOptions struct {
 	// General
 	concurrency int
 	logger      types.Logger
+	pushJobs    int
 
 	// Go
+	builder         types.Builder
+	buildDir        string
+	buildTags       []string
 	cgoEnabled      bool
+	compiler        string
+	env             []string
+	goWork          string
 	ldflags         string
 	mainPath        string
 	modFlag         string
+	overrides       map[types.Platform]PlatformOptions
+	platformOptions map[string]PlatformOptions
+	prebuilt        map[string]string
 	trimpathEnabled bool
+	workdir         string
 
 	// Build/Publish
 	base             string
 	compressionLevel int
+	createRepository bool
 	daemon           string
+	dryRun           string
+	immutableTags    []string
 	load             bool
+	maxBinarySize    int64
+	maxImageSize     int64
 	output           string
 	labels           map[string]string
+	layerCompression string
 	platforms        []string
-	repository       string
+	platformTags     bool
+	repositories     []string
+	since            string
+	sizeBudgetMode   string
+	skipIfExists     bool
 	tags             []string
+	tagStrategy      string
+	tagTemplates     []string
+	upx              bool
+
+	// Registry
+	caFiles            []string
+	credentials        map[string]types.Credential
+	insecureRegistries []string
+	registryMirrors    map[string]string
+	registryTimeout    time.Duration
+	retries            int
+	retryBackoff       time.Duration
 }
 
 func defaultRunOptions() *runOptions {
 	return &runOptions{
 		concurrency: runtime.GOMAXPROCS(0),
 		logger:      StdErrLogger(),
+		pushJobs:    4,
 
+		builder:         nil,
+		buildDir:        "",
+		buildTags:       nil,
 		cgoEnabled:      false,
+		compiler:        CompilerGo,
+		env:             nil,
+		goWork:          "",
 		ldflags:         "-s -w",
 		mainPath:        ".",
 		modFlag:         "",
+		overrides:       nil,
+		platformOptions: nil,
+		prebuilt:        nil,
 		trimpathEnabled: true,
+		workdir:         "",
 
 		base:             "gcr.io/distroless/static:nonroot",
 		compressionLevel: gzip.DefaultCompression,
+		createRepository: false,
 		daemon:           "",
+		dryRun:           "",
+		immutableTags:    nil,
 		load:             false,
+		maxBinarySize:    0,
+		maxImageSize:     0,
 		output:           "",
 		labels:           nil,
-		platforms:        []string{types.DefaultPlatform.String()},
-		repository:       "",
-		tags:             []string{oci.DefaultTag},
+		layerCompression: string(compression.GZip),
+		platforms:        nil,
+		platformTags:     false,
+		repositories:     nil,
+		since:            "",
+		sizeBudgetMode:   SizeBudgetFail,
+		skipIfExists:     false,
+		tags:             nil,
+		tagStrategy:      "",
+		tagTemplates:     nil,
+		upx:              false,
+
+		caFiles:            nil,
+		credentials:        nil,
+		insecureRegistries: nil,
+		registryMirrors:    nil,
+		registryTimeout:    0,
+		retries:            2,
+		retryBackoff:       time.Second,
+	}
+}
+
+// baseFor returns the base image of platform.
+func (ro *runOptions) baseFor(platform types.Platform) string {
+	if po, ok := ro.overrides[platform]; ok && po.Base != "" {
+		return po.Base
+	}
+	return ro.base
+}
+
+func (ro *runOptions) registryOptions() []oci.RegistryOption {
+	return []oci.RegistryOption{
+		oci.WithCAFiles(ro.caFiles),
+		oci.WithCredentials(ro.credentialsWithEnv()),
+		oci.WithInsecureRegistries(ro.insecureRegistries),
+		oci.WithMirrors(ro.registryMirrors),
+		oci.WithRetries(ro.retries),
+		oci.WithRetryBackoff(ro.retryBackoff),
+		oci.WithTimeout(ro.registryTimeout),
+	}
+}
+
+// credentialsWithEnv returns the configured credentials, adding credentials
+// from the environment for each destination registry that has none.
+func (ro *runOptions) credentialsWithEnv() map[string]types.Credential {
+	username, password := os.Getenv(EnvRegistryUsername), os.Getenv(EnvRegistryPassword)
+	if username == "" && password == "" {
+		return ro.credentials
+	}
+
+	configured := make(map[string]bool, len(ro.credentials))
+	for registry := range ro.credentials {
+		if reg, err := name.NewRegistry(registry); err == nil {
+			configured[reg.RegistryStr()] = true
+		}
+	}
+
+	out := make(map[string]types.Credential, len(ro.credentials)+len(ro.repositories))
+	maps.Copy(out, ro.credentials)
+	for _, raw := range ro.repositories {
+		repo, err := name.NewRepository(raw)
+		if err != nil || configured[repo.RegistryStr()] {
+			continue
+		}
+		out[repo.RegistryStr()] = types.Credential{Username: username, Password: password}
 	}
+	return out
 }
@@ -0,0 +1,139 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/git"
+	"github.com/ryanfowler/gopack/internal/golang"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// RunPackages calls Run for every main package matching the pattern, such as
+// "./cmd/...". With WithSince, packages whose builds do not depend on any file
+// changed since the git ref are skipped: the files in the directories of the
+// package and its dependencies for any of the platforms, embedded files, and
+// the go.mod, go.sum and go.work files involved.
+//
+// When the pattern contains "...", each repository is a prefix that the
+// binary name of every package is appended to. Packages are built in order
+// and, if one fails, the results of those already built are returned with
+// the error, followed by the failed package's result if Run returned one.
+func RunPackages(ctx context.Context, pattern string, options ...RunOption) (*PackagesResult, error) {
+	opts := defaultRunOptions()
+	for _, o := range options {
+		o(opts)
+	}
+	if !fromSource(opts) {
+		return nil, errors.New("cannot build packages matching a pattern with prebuilt binaries or a custom builder")
+	}
+	wildcard := strings.Contains(pattern, "...")
+	if wildcard && opts.output != "" {
+		return nil, errors.New("cannot write the images of several packages to one output")
+	}
+	if err := setPatternRoot(opts, pattern); err != nil {
+		return nil, err
+	}
+	if err := golang.CheckModFlag(ctx, goOptions(opts)...); err != nil {
+		return nil, err
+	}
+	if len(opts.platforms) == 0 {
+		opts.platforms = []string{types.DefaultPlatform.String()}
+	}
+	platforms, err := parsePlatforms(opts.platforms)
+	if err != nil {
+		return nil, err
+	}
+	if err := parsePlatformOptions(opts); err != nil {
+		return nil, err
+	}
+	pkgs, err := golang.ListMainPackages(ctx, opts.mainPath, platforms, goOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	if opts.since != "" {
+		if changed, err = git.ChangedFiles(ctx, opts.workdir, opts.since); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &PackagesResult{Results: []*Result{}}
+	for _, pkg := range pkgs {
+		if opts.since != "" && !slices.ContainsFunc(changed, pkg.DependsOn) {
+			opts.logger.Printf("Skipping %s: not affected by changes since %s\n", pkg.ImportPath, opts.since)
+			res.Skipped = append(res.Skipped, pkg.ImportPath)
+			continue
+		}
+
+		pkgOptions := append(slices.Clip(options), WithWorkdir(opts.buildDir), WithMainPath(pkg.ImportPath))
+		if wildcard && len(opts.repositories) > 0 {
+			binName := golang.BinName(pkg.ImportPath)
+			repositories := make([]string, len(opts.repositories))
+			for i, repo := range opts.repositories {
+				repositories[i] = strings.TrimSuffix(repo, "/") + "/" + binName
+			}
+			pkgOptions = append(pkgOptions, WithRepositories(repositories))
+		}
+		pkgRes, err := Run(ctx, pkgOptions...)
+		if pkgRes != nil {
+			res.Results = append(res.Results, pkgRes)
+		}
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", pkg.ImportPath, err)
+		}
+	}
+	return res, nil
+}
+
+// setPatternRoot sets the build directory and main path used to list the
+// packages matching pattern. Like a local main path, a local pattern is
+// listed from the root of the module containing it.
+func setPatternRoot(opts *runOptions, pattern string) error {
+	opts.buildDir = opts.workdir
+	opts.mainPath = pattern
+	if !isLocalPath(opts.workdir, pattern) {
+		return nil
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(opts.workdir, pattern)
+	}
+	abs, err := filepath.Abs(pattern)
+	if err != nil {
+		return err
+	}
+	dir, _, _ := strings.Cut(abs, "...")
+	root, ok := golang.ModuleRoot(dir)
+	if !ok {
+		return nil
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return err
+	}
+	opts.buildDir = root
+	opts.mainPath = "./" + filepath.ToSlash(rel)
+	if rel == "." {
+		opts.mainPath = "."
+	}
+	return nil
+}
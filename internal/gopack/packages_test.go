@@ -0,0 +1,122 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/testutil"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestRunPackagesSince(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	dir := initGitRepo(t)
+	testutil.WriteFiles(t, dir, map[string]string{
+		"go.mod":          "module example.com/mono\n\ngo 1.21\n",
+		"cmd/api/main.go": "package main\n\nimport \"example.com/mono/lib\"\n\nfunc main() { lib.Run() }\n",
+		"cmd/web/main.go": "package main\n\nfunc main() {}\n",
+		"lib/lib.go":      "package lib\n\nfunc Run() {}\n",
+	})
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "add packages")
+	runGit(t, dir, "tag", "base")
+	t.Chdir(dir)
+
+	run := func(options ...RunOption) *PackagesResult {
+		t.Helper()
+		res, err := RunPackages(context.Background(), "./...", append([]RunOption{
+			WithLogger(NopLogger()),
+			WithBase(host + "/base:latest"),
+			WithRepository(host + "/mono"),
+			WithDryRun(DryRunPlan),
+		}, options...)...)
+		if err != nil {
+			t.Fatalf("RunPackages() error = %v", err)
+		}
+		return res
+	}
+
+	res := run()
+	if len(res.Results) != 2 || res.Results[0].Repository != host+"/mono/api" || res.Results[1].Repository != host+"/mono/web" {
+		t.Fatalf("RunPackages() = %+v, want api and web", res)
+	}
+
+	res = run(WithSince("base"))
+	if len(res.Results) != 0 || !slices.Equal(res.Skipped, []string{"example.com/mono/cmd/api", "example.com/mono/cmd/web"}) {
+		t.Fatalf("RunPackages() = %+v, want every package skipped", res)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "lib", "lib.go"), []byte("package lib\n\nfunc Run() { println() }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res = run(WithSince("base"))
+	if len(res.Results) != 1 || res.Results[0].Repository != host+"/mono/api" || !slices.Equal(res.Skipped, []string{"example.com/mono/cmd/web"}) {
+		t.Fatalf("RunPackages() = %+v, want only api built", res)
+	}
+
+	runGit(t, dir, "commit", "-q", "-am", "change lib")
+	runGit(t, dir, "tag", "lib")
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res = run(WithSince("lib"))
+	if len(res.Results) != 2 || len(res.Skipped) != 0 {
+		t.Fatalf("RunPackages() = %+v, want every package built after a go.sum change", res)
+	}
+}
+
+func TestRunPackagesReturnsPartialResult(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	dir := t.TempDir()
+	testutil.WriteFiles(t, dir, map[string]string{
+		"go.mod":          "module example.com/mono\n\ngo 1.21\n",
+		"cmd/api/main.go": "package main\n\nfunc main() {}\n",
+		"cmd/web/main.go": "package main\n\nfunc main() { undefined() }\n",
+	})
+
+	res, err := RunPackages(context.Background(), "./...",
+		WithLogger(NopLogger()),
+		WithWorkdir(dir),
+		WithBase(host+"/base:latest"),
+		WithRepository(host+"/mono"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "example.com/mono/cmd/web") {
+		t.Fatalf("RunPackages() error = %v, want web build error", err)
+	}
+	if res == nil || len(res.Results) != 1 || res.Results[0].Repository != host+"/mono/api" {
+		t.Fatalf("RunPackages() = %+v, want the pushed api result", res)
+	}
+}
+
+func TestRunPackagesValidation(t *testing.T) {
+	_, err := RunPackages(context.Background(), "./...", WithLogger(NopLogger()), WithOutput("oci:image.tar"))
+	if err == nil || !strings.Contains(err.Error(), "one output") {
+		t.Fatalf("RunPackages() error = %v, want output error", err)
+	}
+	_, err = RunPackages(context.Background(), "./...", WithLogger(NopLogger()), WithBuilder(&fakeBuilder{}))
+	if err == nil || !strings.Contains(err.Error(), "custom builder") {
+		t.Fatalf("RunPackages() error = %v, want builder error", err)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"golang.org/x/sync/errgroup"
+)
+
+// pipeline is the outcome of building every platform with buildPipeline.
+type pipeline struct {
+	bases *bases
+	imgs  map[types.Platform]v1.Image
+	// uploaded reports whether every image was uploaded as soon as it was
+	// built, leaving only the final manifest and tags to push.
+	uploaded bool
+
+	resolve     time.Duration
+	build       time.Duration
+	uploadStart time.Time
+}
+
+// buildPipeline resolves the base images while every platform is compiled.
+// Each binary is layered onto its platform's base as soon as both are ready
+// and, when uploadsEarly allows it, the image is uploaded right away, so that
+// different platforms are compiled, layered and uploaded at the same time. Up
+// to opts.concurrency platforms are compiled or layered at a time, and up to
+// opts.pushJobs images are uploaded at a time. Layers are written to dir,
+// which must be kept until the images have been written.
+func buildPipeline(ctx context.Context, dir string, platforms []types.Platform, binName string, opts *runOptions, progress types.Progress) (*pipeline, error) {
+	start := time.Now()
+	if len(opts.platforms) == 1 {
+		opts.logger.Printf("Building image for platform %s\n", opts.platforms[0])
+	} else {
+		opts.logger.Printf("Building images for platforms %v\n", opts.platforms)
+	}
+
+	p := &pipeline{
+		imgs:     make(map[types.Platform]v1.Image, len(platforms)),
+		uploaded: uploadsEarly(opts),
+	}
+	var repos []name.Repository
+	if p.uploaded {
+		var err error
+		if repos, err = parseRepositories(opts.repositories); err != nil {
+			return nil, err
+		}
+	}
+	var packer *upxPacker
+	if opts.upx {
+		packer = newUPXPacker(opts.logger)
+	}
+	buildSem := make(chan struct{}, max(opts.concurrency, 1))
+	pushSem := make(chan struct{}, max(opts.pushJobs, 1))
+	baseReady := make(chan struct{})
+	var mu sync.Mutex
+
+	// The errgroup context is canceled once Wait returns, so the parent ctx
+	// is checked for cancellation afterwards.
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		// The base's layers are read lazily with the context it was fetched
+		// with, which must outlive the errgroup.
+		bases, err := resolveBases(ctx, platforms, opts)
+		if err != nil {
+			return err
+		}
+		p.bases = bases
+		p.resolve = time.Since(start)
+		close(baseReady)
+		return nil
+	})
+
+	seen := make(map[types.Platform]bool, len(platforms))
+	for _, platform := range platforms {
+		if seen[platform] {
+			continue
+		}
+		seen[platform] = true
+
+		eg.Go(func() error {
+			// The binary is removed once it is layered, while uploads may
+			// still be running, but the layer stays in dir until the image
+			// has been written.
+			binDir, err := os.MkdirTemp(dir, "build-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(binDir)
+			binPath := filepath.Join(binDir, binName)
+
+			err = withSemaphore(egCtx, buildSem, func() error {
+				return compile(egCtx, opts.builder, packer, binPath, platform, opts, progress)
+			})
+			if err != nil {
+				return fmt.Errorf("building %s: %w", platform, err)
+			}
+
+			select {
+			case <-baseReady:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+			var img v1.Image
+			err = withSemaphore(egCtx, buildSem, func() error {
+				img, err = layer(egCtx, dir, binPath, platform, p.bases.imgs[platform], opts, progress)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("building %s: %w", platform, err)
+			}
+			os.RemoveAll(binDir)
+
+			mu.Lock()
+			p.imgs[platform] = img
+			p.build = time.Since(start)
+			mu.Unlock()
+			if !p.uploaded {
+				return nil
+			}
+
+			return withSemaphore(egCtx, pushSem, func() error {
+				mu.Lock()
+				if p.uploadStart.IsZero() {
+					p.uploadStart = time.Now()
+				}
+				mu.Unlock()
+				return uploadImage(egCtx, repos, platform, img, opts, progress)
+			})
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return p, nil
+}
+
+// uploadsEarly reports whether images are uploaded as soon as they are built.
+// That is only done when pushing to registries, and not when existing tags or
+// digests must be checked, or a size budget must pass, before anything is
+// uploaded.
+func uploadsEarly(opts *runOptions) bool {
+	budgets := opts.maxImageSize > 0 || opts.maxBinarySize > 0
+	return opts.output == "" && opts.daemon == "" && opts.dryRun == "" &&
+		!opts.skipIfExists && len(opts.immutableTags) == 0 &&
+		(!budgets || opts.sizeBudgetMode != SizeBudgetFail)
+}
+
+// withSemaphore runs fn once a slot in sem is free, unless ctx is done first.
+func withSemaphore(ctx context.Context, sem chan struct{}, fn func() error) error {
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-sem }()
+	return fn()
+}
+
+// compile builds the binary for the platform at binPath, packing it with
+// packer if set.
+func compile(ctx context.Context, builder types.Builder, packer *upxPacker, binPath string, p types.Platform, opts *runOptions, progress types.Progress) error {
+	progress.Phase(p.String(), "compiling")
+	if err := builder.Build(ctx, binPath, p); err != nil {
+		return err
+	}
+	opts.logger.Debugf("Built %s binary for %s\n", filepath.Base(binPath), p)
+
+	if packer != nil {
+		progress.Phase(p.String(), "packing")
+		if err := packer.Pack(ctx, binPath, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layer builds the platform's image by adding the binary at binPath to img,
+// writing the layer to dir.
+func layer(ctx context.Context, dir, binPath string, p types.Platform, img v1.Image, opts *runOptions, progress types.Progress) (v1.Image, error) {
+	progress.Phase(p.String(), "layering")
+	buildOptions := []oci.BuildOption{
+		oci.WithCompression(compression.Compression(opts.layerCompression)),
+		oci.WithCompressionLevel(opts.compressionLevel),
+		oci.WithLabels(opts.labels),
+		oci.WithLayerDir(dir),
+	}
+	out, err := oci.BuildImage(ctx, binPath, img, buildOptions...)
+	if err != nil {
+		return nil, err
+	}
+	progress.Phase(p.String(), "built")
+	return out, nil
+}
+
+// uploadImage uploads the platform's image to the repositories by digest.
+func uploadImage(ctx context.Context, repos []name.Repository, p types.Platform, img v1.Image, opts *runOptions, progress types.Progress) error {
+	tasks, err := blobTasks(map[types.Platform]v1.Image{p: img})
+	if err != nil {
+		return err
+	}
+	progress.Phase(p.String(), "uploading")
+	err = oci.Upload(ctx, repos, img,
+		oci.WithCreateRepository(opts.createRepository),
+		oci.WithLogger(opts.logger),
+		oci.WithProgress(progress, tasks),
+		oci.WithRegistryOptions(opts.registryOptions()...))
+	if err != nil {
+		return fmt.Errorf("uploading %s: %w", p, err)
+	}
+	return nil
+}
+
+func parseRepositories(raw []string) ([]name.Repository, error) {
+	repos := make([]name.Repository, 0, len(raw))
+	for _, r := range raw {
+		repo, err := name.NewRepository(r)
+		if err != nil {
+			return nil, fmt.Errorf("push: parsing repository %q: %w", r, err)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
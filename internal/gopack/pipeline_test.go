@@ -0,0 +1,208 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type builderFunc func(ctx context.Context, outPath string, platform types.Platform) error
+
+func (f builderFunc) Build(ctx context.Context, outPath string, platform types.Platform) error {
+	return f(ctx, outPath, platform)
+}
+
+func TestRunPipelinesBuildAndPush(t *testing.T) {
+	buildStarted := make(chan struct{})
+	uploaded := make(chan struct{})
+	var startOnce, uploadOnce sync.Once
+	var gated atomic.Bool
+
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if gated.Load() && strings.HasPrefix(r.URL.Path, "/v2/base/manifests/") {
+			// The base is only resolved once a build has started.
+			select {
+			case <-buildStarted:
+			case <-time.After(5 * time.Second):
+				http.Error(w, "base resolved before building", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+		if r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v2/app/manifests/sha256:") {
+			uploadOnce.Do(func() { close(uploaded) })
+		}
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := u.Host
+
+	base := makeImageIndex(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/amd64"): imageWithPlatform(t, types.ParsePlatform("linux/amd64")),
+		types.ParsePlatform("linux/arm64"): imageWithPlatform(t, types.ParsePlatform("linux/arm64")),
+	}, "")
+	if err := remote.WriteIndex(mustParseReference(t, host+"/base:latest"), base); err != nil {
+		t.Fatal(err)
+	}
+	gated.Store(true)
+
+	// The arm64 build only finishes once the amd64 image has been uploaded.
+	builder := builderFunc(func(ctx context.Context, outPath string, platform types.Platform) error {
+		startOnce.Do(func() { close(buildStarted) })
+		if platform.Arch() == "arm64" {
+			select {
+			case <-uploaded:
+			case <-time.After(5 * time.Second):
+				return errors.New("no image was uploaded before the last build finished")
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return os.WriteFile(outPath, []byte(platform.String()), 0o755)
+	})
+
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithBuilder(builder),
+		WithMainPath("example.com/app"),
+		WithRepository(host+"/app"),
+		WithPlatforms([]string{"linux/amd64", "linux/arm64"}),
+		WithConcurrency(2),
+		WithPushJobs(1),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	desc, err := remote.Head(mustParseReference(t, host+"/app:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest.String() != res.Digest || len(res.Images) != 2 {
+		t.Fatalf("Result = %+v, want index %s with two images", res, desc.Digest)
+	}
+}
+
+func TestRunWritesArchiveFromLayerFiles(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	path := filepath.Join(t.TempDir(), "image.tar")
+	content := strings.Repeat("binary", 1<<16)
+
+	// The layers are read when the archive is written, after every binary
+	// has been removed.
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithBuilder(&fakeBuilder{content: content}),
+		WithMainPath("example.com/app"),
+		WithOutput("oci:"+path),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	a, err := oci.OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	out, err := archiveManifest(a.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgs, err := archiveImages(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := imgs[types.ParsePlatform("linux/amd64")]
+	if digest, err := img.Digest(); err != nil || digest.String() != res.Images[0].Digest {
+		t.Fatalf("archived image digest = %s, %v, want %s", digest, err, res.Images[0].Digest)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := layers[len(layers)-1].Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	if _, err := tr.Next(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Fatalf("layer contains %d bytes, want the %d byte binary", len(got), len(content))
+	}
+}
+
+func TestUploadsEarly(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []RunOption
+		want    bool
+	}{
+		{name: "registry", want: true},
+		{name: "output", options: []RunOption{WithOutput("oci:image.tar")}},
+		{name: "daemon", options: []RunOption{WithLoad(true)}},
+		{name: "dry run", options: []RunOption{WithDryRun(DryRunBuild)}},
+		{name: "skip if exists", options: []RunOption{WithSkipIfExists(true)}},
+		{name: "immutable tags", options: []RunOption{WithImmutableTags([]string{"*"})}},
+		{name: "size budget", options: []RunOption{WithMaxImageSize(1 << 20)}},
+		{name: "warning size budget", options: []RunOption{WithMaxImageSize(1 << 20), WithSizeBudgetMode(SizeBudgetWarn)}, want: true},
+	}
+	for _, test := range tests {
+		opts := defaultRunOptions()
+		for _, o := range test.options {
+			o(opts)
+		}
+		if opts.load {
+			opts.daemon = dockerDaemon
+		}
+		if got := uploadsEarly(opts); got != test.want {
+			t.Errorf("%s: uploadsEarly() = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"fmt"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// DryRunPlan resolves the base, validates the platforms and computes the
+	// destinations without compiling or writing anything.
+	DryRunPlan = "plan"
+	// DryRunBuild additionally compiles and layers every image so that the
+	// planned digests are known, but still writes nothing.
+	DryRunBuild = "build"
+)
+
+func validateDryRun(mode string) error {
+	switch mode {
+	case "", DryRunPlan, DryRunBuild:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dry run mode %q (supported: %s, %s)", mode, DryRunPlan, DryRunBuild)
+	}
+}
+
+// describeBases describes the image planned for each platform before it is
+// built, which is only known by its base.
+func describeBases(bases map[types.Platform]v1.Image) ([]ImageResult, error) {
+	out := make([]ImageResult, 0, len(bases))
+	for _, platform := range sortedPlatforms(bases) {
+		out = append(out, ImageResult{Platform: platform.String()})
+	}
+	if err := setBaseDigests(out, bases); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// setPlannedReferences sets the references an unbuilt image would be
+// reported by. Without a digest, the first tag is used if every tag is the
+// default tag.
+func setPlannedReferences(res *Result, opts *runOptions) {
+	if opts.output != "" {
+		return
+	}
+	tag, ok := preferredTag(opts.tags)
+	if !ok {
+		tag = opts.tags[0]
+	}
+	res.References = make([]string, 0, len(opts.repositories))
+	for _, repo := range opts.repositories {
+		res.References = append(res.References, repo+":"+tag)
+	}
+	res.Reference = res.References[0]
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// elfArchs maps each supported architecture to the ELF machine and class of
+// its executables.
+var elfArchs = map[string]struct {
+	machine elf.Machine
+	class   elf.Class
+}{
+	"386":   {elf.EM_386, elf.ELFCLASS32},
+	"amd64": {elf.EM_X86_64, elf.ELFCLASS64},
+	"arm":   {elf.EM_ARM, elf.ELFCLASS32},
+	"arm64": {elf.EM_AARCH64, elf.ELFCLASS64},
+}
+
+// prebuiltPlatforms returns the platforms of the prebuilt binaries.
+func prebuiltPlatforms(binaries map[string]string) map[types.Platform]string {
+	out := make(map[types.Platform]string, len(binaries))
+	for raw, path := range binaries {
+		out[types.ParsePlatform(raw)] = path
+	}
+	return out
+}
+
+// prebuiltBuilder is a types.Builder that copies binaries built elsewhere,
+// such as by Bazel or goreleaser, after validating them.
+type prebuiltBuilder struct {
+	paths  map[types.Platform]string
+	logger types.Logger
+}
+
+// newPrebuiltBuilder returns a builder for binaries by platform. Every binary
+// must exist.
+func newPrebuiltBuilder(binaries map[string]string, logger types.Logger) (*prebuiltBuilder, error) {
+	paths := make(map[types.Platform]string, len(binaries))
+	for raw, path := range binaries {
+		platform := types.ParsePlatform(raw)
+		if !platform.IsSupported() {
+			return nil, fmt.Errorf("prebuilt: unsupported platform %q", raw)
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("prebuilt binary for %s: %w", platform, err)
+		}
+		if !stat.Mode().IsRegular() {
+			return nil, fmt.Errorf("prebuilt binary for %s: %s is not a regular file", platform, path)
+		}
+		paths[platform] = path
+	}
+	return &prebuiltBuilder{paths: paths, logger: logger}, nil
+}
+
+// CheckPlatform returns an error if platform has no prebuilt binary.
+func (b *prebuiltBuilder) CheckPlatform(platform types.Platform) error {
+	if _, ok := b.paths[platform]; !ok {
+		return fmt.Errorf("no prebuilt binary for %s", platform)
+	}
+	return nil
+}
+
+// Build validates the binary for platform and copies it to outPath.
+func (b *prebuiltBuilder) Build(ctx context.Context, outPath string, platform types.Platform) error {
+	if err := b.CheckPlatform(platform); err != nil {
+		return err
+	}
+	path := b.paths[platform]
+	if err := b.validate(path, platform); err != nil {
+		return fmt.Errorf("prebuilt binary %s: %w", path, err)
+	}
+	return copyExecutable(path, outPath)
+}
+
+// validate returns an error unless path is a Linux executable for the
+// architecture of platform.
+func (b *prebuiltBuilder) validate(path string, platform types.Platform) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		var formatErr *elf.FormatError
+		if errors.As(err, &formatErr) {
+			return errors.New("not an ELF executable")
+		}
+		return err
+	}
+	defer f.Close()
+
+	if f.Type != elf.ET_EXEC && f.Type != elf.ET_DYN {
+		return fmt.Errorf("not an executable (ELF type %s)", f.Type)
+	}
+	if f.OSABI != elf.ELFOSABI_NONE && f.OSABI != elf.ELFOSABI_LINUX {
+		return fmt.Errorf("built for %s, not linux", f.OSABI)
+	}
+	want, ok := elfArchs[platform.Arch()]
+	if !ok {
+		return fmt.Errorf("unsupported architecture %s", platform.Arch())
+	}
+	if f.Machine != want.machine || f.Class != want.class || f.ByteOrder != binary.LittleEndian {
+		return fmt.Errorf("built for %s (%s), not %s", f.Machine, f.Class, platform.Arch())
+	}
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			b.logger.Warnf("prebuilt binary %s for %s is dynamically linked; the base image must provide its libraries\n", path, platform)
+			break
+		}
+	}
+	return nil
+}
+
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
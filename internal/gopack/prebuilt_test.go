@@ -0,0 +1,198 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestRunPrebuilt(t *testing.T) {
+	host := newTestRegistry(t)
+	base := makeImageIndex(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/amd64"): imageWithPlatform(t, types.ParsePlatform("linux/amd64")),
+		types.ParsePlatform("linux/arm64"): imageWithPlatform(t, types.ParsePlatform("linux/arm64")),
+	}, "")
+	if err := remote.WriteIndex(mustParseReference(t, host+"/base:latest"), base); err != nil {
+		t.Fatal(err)
+	}
+	mainPath := writeTestMain(t)
+	amd64 := goBuildTestBinary(t, mainPath, "amd64")
+	arm64 := goBuildTestBinary(t, mainPath, "arm64")
+
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath(mainPath),
+		WithRepository(host+"/app"),
+		WithDryRun(DryRunBuild),
+		WithPrebuilt(map[string]string{"linux/amd64": amd64, "linux/arm64": arm64}),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Images) != 2 || res.Images[0].Platform != "linux/amd64" || res.Images[1].Platform != "linux/arm64" {
+		t.Fatalf("Result.Images = %+v, want the prebuilt platforms", res.Images)
+	}
+}
+
+func TestPrebuiltBuilderCopiesBinary(t *testing.T) {
+	mainPath := writeTestMain(t)
+	bin := goBuildTestBinary(t, mainPath, "arm64")
+
+	builder, err := newPrebuiltBuilder(map[string]string{"linux/arm64": bin}, NopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "app")
+	if err := builder.Build(context.Background(), out, types.ParsePlatform("linux/arm64")); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want, err := os.ReadFile(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("copied binary differs from the prebuilt binary")
+	}
+}
+
+func TestPrebuiltBuilderValidatesBinaries(t *testing.T) {
+	mainPath := writeTestMain(t)
+	arm64 := goBuildTestBinary(t, mainPath, "arm64")
+	script := filepath.Join(t.TempDir(), "app.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		platform string
+		path     string
+		want     string
+	}{
+		{"linux/amd64", arm64, "built for EM_AARCH64 (ELFCLASS64), not amd64"},
+		{"linux/amd64", script, "not an ELF executable"},
+	}
+	for _, test := range tests {
+		builder, err := newPrebuiltBuilder(map[string]string{test.platform: test.path}, NopLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = builder.Build(context.Background(), filepath.Join(t.TempDir(), "app"), types.ParsePlatform(test.platform))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("Build(%s) error = %v, want %q", test.path, err, test.want)
+		}
+	}
+
+	_, err := Run(context.Background(),
+		WithMainPath(mainPath),
+		WithPlatforms([]string{"linux/amd64", "linux/arm64"}),
+		WithPrebuilt(map[string]string{"linux/arm64": arm64}),
+	)
+	if err == nil || !strings.Contains(err.Error(), "no prebuilt binary for linux/amd64") {
+		t.Fatalf("Run() error = %v, want missing prebuilt binary error", err)
+	}
+}
+
+func TestRunCustomBuilder(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	mainPath := writeTestMain(t)
+
+	builder := &fakeBuilder{content: "binary"}
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithMainPath(mainPath),
+		WithRepository(host+"/app"),
+		WithBuilder(builder),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(builder.platforms) != 1 || builder.platforms[0] != "linux/amd64" {
+		t.Fatalf("builder platforms = %v, want [linux/amd64]", builder.platforms)
+	}
+
+	img, err := remote.Image(mustParseReference(t, res.Reference))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := layers[len(layers)-1].Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	tr := tar.NewReader(rc)
+	hdr, err := tr.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "app/"+filepath.Base(mainPath) || string(content) != builder.content {
+		t.Fatalf("layer entry = %s %q, want the builder's binary", hdr.Name, content)
+	}
+}
+
+type fakeBuilder struct {
+	content string
+
+	mu        sync.Mutex
+	platforms []string
+}
+
+func (b *fakeBuilder) Build(ctx context.Context, outPath string, platform types.Platform) error {
+	b.mu.Lock()
+	b.platforms = append(b.platforms, platform.String())
+	b.mu.Unlock()
+	return os.WriteFile(outPath, []byte(b.content), 0o755)
+}
+
+func goBuildTestBinary(t *testing.T, dir, arch string) string {
+	t.Helper()
+
+	out := filepath.Join(t.TempDir(), "app_"+arch)
+	cmd := exec.Command("go", "build", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+arch, "CGO_ENABLED=0")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v: %s", err, output)
+	}
+	return out
+}
@@ -0,0 +1,314 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+var (
+	_ progress = (*ttyDisplay)(nil)
+	_ progress = (*logProgress)(nil)
+)
+
+const displayInterval = 100 * time.Millisecond
+
+// progress is a types.Progress that must be closed once all work is done.
+type progress interface {
+	types.Progress
+	Close()
+}
+
+// newProgress returns a multi-line display when logging as text to a
+// terminal, and a progress that logs phase transitions otherwise.
+func newProgress(l types.Logger) progress {
+	if dl, ok := l.(*defaultLogger); ok && dl.isTerminal && dl.level <= types.LevelInfo {
+		return dl.startDisplay()
+	}
+	return &logProgress{
+		l:      l,
+		phases: make(map[string]string),
+		done:   make(map[string]bool),
+		tasks:  make(map[string]*taskState),
+	}
+}
+
+// taskState is the last known state of a single task.
+type taskState struct {
+	name     string
+	phase    string
+	blobs    map[string]types.BlobState
+	complete int64
+	total    int64
+	started  time.Time
+	last     time.Time
+}
+
+func (t *taskState) setPhase(phase string) bool {
+	if t.phase == phase {
+		return false
+	}
+	t.phase = phase
+	return true
+}
+
+func (t *taskState) setBlob(digest string, state types.BlobState) {
+	if t.blobs == nil {
+		t.blobs = make(map[string]types.BlobState)
+	}
+	t.blobs[digest] = state
+	if t.phase != "pushed" {
+		t.phase = "uploading"
+	}
+}
+
+func (t *taskState) setTransfer(complete, total int64) {
+	now := time.Now()
+	if t.started.IsZero() {
+		t.started = now
+	}
+	t.last = now
+	t.complete = complete
+	t.total = total
+	if t.phase == "" {
+		t.phase = "uploading"
+	}
+}
+
+// rate returns the average transfer rate in bytes per second.
+func (t *taskState) rate() float64 {
+	elapsed := t.last.Sub(t.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(t.complete) / elapsed
+}
+
+func (t *taskState) detail() string {
+	var parts []string
+	if len(t.blobs) > 0 {
+		var done, skipped int
+		for _, state := range t.blobs {
+			switch state {
+			case types.BlobUploaded, types.BlobMounted:
+				done++
+			case types.BlobSkipped:
+				skipped++
+			}
+		}
+		s := fmt.Sprintf("%d/%d blobs", done, len(t.blobs)-skipped)
+		if skipped > 0 {
+			s += fmt.Sprintf(", %d skipped", skipped)
+		}
+		parts = append(parts, s)
+	}
+	if t.total > 0 {
+		parts = append(parts, fmt.Sprintf("%s/%s MB (%s MB/s)",
+			bytesToMegaBytes(t.complete), bytesToMegaBytes(t.total),
+			bytesToMegaBytes(int64(t.rate()))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ttyDisplay renders one line per task, redrawing them in place. Messages
+// logged through the owning defaultLogger are printed above the lines.
+type ttyDisplay struct {
+	l      *defaultLogger
+	tasks  []*taskState
+	byName map[string]*taskState
+	drawn  int
+	dirty  bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (l *defaultLogger) startDisplay() *ttyDisplay {
+	d := &ttyDisplay{
+		l:      l,
+		byName: make(map[string]*taskState),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	l.mu.Lock()
+	l.display = d
+	l.mu.Unlock()
+
+	go d.loop()
+	return d
+}
+
+func (d *ttyDisplay) loop() {
+	defer close(d.done)
+	ticker := time.NewTicker(displayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.l.mu.Lock()
+			if d.dirty {
+				d.draw()
+			}
+			d.l.mu.Unlock()
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *ttyDisplay) Close() {
+	close(d.stop)
+	<-d.done
+
+	d.l.mu.Lock()
+	defer d.l.mu.Unlock()
+	if d.dirty {
+		d.draw()
+	}
+	d.l.display = nil
+}
+
+func (d *ttyDisplay) Phase(task, phase string) {
+	d.update(task, func(t *taskState) { t.setPhase(phase) })
+}
+
+func (d *ttyDisplay) Blob(task, digest string, state types.BlobState) {
+	d.update(task, func(t *taskState) { t.setBlob(digest, state) })
+}
+
+func (d *ttyDisplay) Transfer(task string, complete, total int64) {
+	d.update(task, func(t *taskState) { t.setTransfer(complete, total) })
+}
+
+func (d *ttyDisplay) update(task string, fn func(*taskState)) {
+	d.l.mu.Lock()
+	defer d.l.mu.Unlock()
+	t, ok := d.byName[task]
+	if !ok {
+		t = &taskState{name: task}
+		d.byName[task] = t
+		d.tasks = append(d.tasks, t)
+	}
+	fn(t)
+	d.dirty = true
+}
+
+// clear erases the drawn lines. The logger's mutex must be held.
+func (d *ttyDisplay) clear() {
+	if d.drawn > 0 {
+		fmt.Fprintf(d.l.w, "\033[%dA\033[J", d.drawn)
+		d.drawn = 0
+	}
+}
+
+// draw redraws every task line in place. The logger's mutex must be held.
+func (d *ttyDisplay) draw() {
+	width := 0
+	for _, t := range d.tasks {
+		width = max(width, len(t.name))
+	}
+	if d.drawn > 0 {
+		fmt.Fprintf(d.l.w, "\033[%dA", d.drawn)
+	}
+	for _, t := range d.tasks {
+		fmt.Fprintf(d.l.w, "\033[2K%-*s  %-10s %s\n", width, t.name, t.phase, t.detail())
+	}
+	d.drawn = len(d.tasks)
+	d.dirty = false
+}
+
+// logProgress logs phase transitions and completed transfers as individual
+// messages. Blob state changes are logged at the debug level.
+type logProgress struct {
+	l      types.Logger
+	mu     sync.Mutex
+	phases map[string]string
+	done   map[string]bool
+	tasks  map[string]*taskState
+}
+
+func (p *logProgress) Phase(task, phase string) {
+	p.mu.Lock()
+	changed := p.phases[task] != phase
+	p.phases[task] = phase
+	p.mu.Unlock()
+	if changed {
+		p.l.Printf("%s: %s\n", task, phase)
+	}
+}
+
+func (p *logProgress) Blob(task, digest string, state types.BlobState) {
+	p.l.Debugf("%s: %s blob %s\n", task, state, digest)
+}
+
+func (p *logProgress) Transfer(task string, complete, total int64) {
+	p.mu.Lock()
+	t, ok := p.tasks[task]
+	if !ok {
+		t = &taskState{name: task}
+		p.tasks[task] = t
+	}
+	t.setTransfer(complete, total)
+	finished := total > 0 && complete >= total && !p.done[task]
+	if finished {
+		p.done[task] = true
+	}
+	p.mu.Unlock()
+
+	if finished {
+		p.l.Printf("%s: uploaded %s MB (%s MB/s)\n", task,
+			bytesToMegaBytes(total), bytesToMegaBytes(int64(t.rate())))
+	}
+}
+
+func (p *logProgress) Close() {}
+
+// blobTasks maps the manifest, config, and layer digests of every image to
+// the platform it was built for.
+func blobTasks(imgs map[types.Platform]v1.Image) (map[string]string, error) {
+	out := make(map[string]string)
+	for platform, img := range imgs {
+		task := platform.String()
+		digest, err := img.Digest()
+		if err != nil {
+			return nil, err
+		}
+		out[digest.String()] = task
+
+		manifest, err := img.Manifest()
+		if err != nil {
+			return nil, err
+		}
+		out[manifest.Config.Digest.String()] = task
+		for _, layer := range manifest.Layers {
+			out[layer.Digest.String()] = task
+		}
+	}
+	return out, nil
+}
+
+func bytesToMegaBytes(bytes int64) string {
+	mb := float64(bytes) / 1_000_000
+	out := strconv.FormatFloat(mb, 'f', 2, 64)
+	out = strings.TrimRight(out, "0")
+	return strings.TrimSuffix(out, ".")
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestTTYDisplay(t *testing.T) {
+	var buf bytes.Buffer
+	l := &defaultLogger{w: &buf, level: types.LevelInfo, isTerminal: true}
+	d := l.startDisplay()
+	d.Phase("linux/amd64", "compiling")
+	d.Phase("linux/arm64", "compiling")
+	l.Printf("message\n")
+	d.Blob("linux/amd64", "sha256:a", types.BlobUploaded)
+	d.Blob("linux/amd64", "sha256:b", types.BlobSkipped)
+	d.Blob("linux/amd64", "sha256:c", types.BlobUploading)
+	d.Close()
+
+	out := buf.String()
+	if !strings.Contains(out, "message\n") {
+		t.Fatalf("output missing logged message: %q", out)
+	}
+	if !strings.Contains(out, "linux/amd64  uploading  1/2 blobs, 1 skipped\n") {
+		t.Fatalf("output missing final amd64 line: %q", out)
+	}
+	if !strings.Contains(out, "linux/arm64  compiling  \n") {
+		t.Fatalf("output missing final arm64 line: %q", out)
+	}
+	if l.display != nil {
+		t.Fatal("display still attached to logger after Close")
+	}
+}
+
+func TestLogProgress(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgress(NewTextLogger(&buf, types.LevelInfo))
+	p.Phase("linux/amd64", "compiling")
+	p.Phase("linux/amd64", "compiling")
+	p.Blob("linux/amd64", "sha256:a", types.BlobUploaded)
+	p.Transfer("app:v1", 500_000, 1_000_000)
+	p.Transfer("app:v1", 1_000_000, 1_000_000)
+	p.Transfer("app:v1", 1_000_000, 1_000_000)
+	p.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	if lines[0] != "linux/amd64: compiling" {
+		t.Fatalf("line 0 = %q, want phase transition", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "app:v1: uploaded 1 MB") {
+		t.Fatalf("line 1 = %q, want completed transfer", lines[1])
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Push pushes the OCI archive at archive ("oci:<path>"), as written by Run,
+// to the configured repositories without rebuilding. If the archive's index
+// only wraps another index, as some tools write, that index is pushed;
+// otherwise the archive's index itself is, even for a single image. Manifests
+// are pushed unchanged, so their digests match those reported by Run.
+//
+// Options that only affect building, such as the base or platforms, are
+// ignored.
+func Push(ctx context.Context, archive string, options ...RunOption) (*Result, error) {
+	start := time.Now()
+	opts := defaultRunOptions()
+	for _, o := range options {
+		o(opts)
+	}
+	path, ok := strings.CutPrefix(archive, ociOutputPrefix)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("unsupported archive %q (supported: oci:<path>)", archive)
+	}
+	if len(opts.repositories) == 0 {
+		return nil, errors.New("push requires a repository")
+	}
+	if opts.output != "" || opts.daemon != "" || opts.load {
+		return nil, errors.New("push only supports pushing to a registry")
+	}
+	if err := validateDryRun(opts.dryRun); err != nil {
+		return nil, err
+	}
+	if opts.retries < 0 {
+		return nil, fmt.Errorf("invalid retries %d: must not be negative", opts.retries)
+	}
+	if err := oci.ValidateTagPatterns(opts.immutableTags); err != nil {
+		return nil, err
+	}
+	tags, err := resolveTags(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.tags = tags
+
+	phase := time.Now()
+	a, err := oci.OpenArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+
+	out, err := archiveManifest(a.Index)
+	if err != nil {
+		return nil, err
+	}
+	imgs, err := archiveImages(out)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Archive: path, DryRun: opts.dryRun}
+	if err := setDestinations(res, opts); err != nil {
+		return nil, err
+	}
+	if opts.platformTags {
+		if err := validatePlatformTags(opts.tags, sortedPlatforms(imgs)); err != nil {
+			return nil, err
+		}
+	}
+	if res.Images, err = describeImages(imgs); err != nil {
+		return nil, err
+	}
+	setPlatformTags(res.Images, opts)
+	res.Durations.Resolve = since(phase)
+
+	if opts.dryRun == "" {
+		progress := newProgress(opts.logger)
+		defer progress.Close()
+
+		phase = time.Now()
+		if err := pushRegistries(ctx, imgs, out, false, opts, progress); err != nil {
+			return nil, err
+		}
+		res.Durations.Push = since(phase)
+	}
+	if err := setOutput(res, opts.repositories, out, opts.tags); err != nil {
+		return nil, err
+	}
+	res.Durations.Total = since(start)
+	return res, nil
+}
+
+// archiveManifest returns the manifest to push from the layout index of an
+// archive. A single image is not unwrapped, as the digest reported when the
+// archive was written is that of the index.
+func archiveManifest(index v1.ImageIndex) (manifest, error) {
+	m, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(m.Manifests) == 0:
+		return nil, errors.New("archive contains no images")
+	case len(m.Manifests) == 1 && m.Manifests[0].MediaType.IsIndex():
+		return index.ImageIndex(m.Manifests[0].Digest)
+	}
+	return index, nil
+}
+
+// archiveImages returns the images of m by platform. Only the first image of
+// each platform is returned, as indexes written by other tools may contain
+// several attestation manifests for the "unknown/unknown" platform; every
+// manifest is still pushed.
+func archiveImages(m manifest) (map[types.Platform]v1.Image, error) {
+	if img, ok := m.(v1.Image); ok {
+		config, err := img.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		return map[types.Platform]v1.Image{platformOf(config.Platform()): img}, nil
+	}
+
+	index := m.(v1.ImageIndex)
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[types.Platform]v1.Image, len(indexManifest.Manifests))
+	for _, desc := range indexManifest.Manifests {
+		if !desc.MediaType.IsImage() {
+			continue
+		}
+		platform := platformOf(desc.Platform)
+		if _, ok := out[platform]; ok {
+			continue
+		}
+		img, err := index.Image(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		out[platform] = img
+	}
+	if len(out) == 0 {
+		return nil, errors.New("archive contains no images")
+	}
+	return out, nil
+}
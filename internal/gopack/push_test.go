@@ -0,0 +1,144 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestPushArchivePreservesIndexDigest(t *testing.T) {
+	host := newTestRegistry(t)
+	index := makeImageIndex(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/amd64"): imageWithPlatform(t, types.ParsePlatform("linux/amd64")),
+		types.ParsePlatform("linux/arm64"): imageWithPlatform(t, types.ParsePlatform("linux/arm64")),
+	}, "")
+	path := writeTestArchive(t, index)
+
+	res, err := Push(context.Background(), "oci:"+path,
+		WithLogger(NopLogger()),
+		WithRepository(host+"/app"),
+		WithTags([]string{"v1"}),
+	)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	want, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Digest != want.String() || res.Reference != host+"/app:v1" {
+		t.Fatalf("Result = %s %s, want %s %s", res.Reference, res.Digest, host+"/app:v1", want)
+	}
+	if len(res.Images) != 2 || res.Archive != path {
+		t.Fatalf("Result = %+v, want 2 images from %s", res, path)
+	}
+	desc, err := remote.Head(mustParseReference(t, host+"/app:v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != want {
+		t.Fatalf("pushed digest = %s, want %s", desc.Digest, want)
+	}
+}
+
+func TestPushArchiveSingleImage(t *testing.T) {
+	host := newTestRegistry(t)
+	index := makeImageIndex(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/arm64"): imageWithPlatform(t, types.ParsePlatform("linux/arm64")),
+	}, "")
+	path := writeTestArchive(t, index)
+
+	res, err := Push(context.Background(), "oci:"+path,
+		WithLogger(NopLogger()),
+		WithRepository(host+"/app"),
+	)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	want, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Digest != want.String() {
+		t.Fatalf("Result.Digest = %s, want index digest %s", res.Digest, want)
+	}
+	desc, err := remote.Head(mustParseReference(t, host+"/app:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != want {
+		t.Fatalf("pushed digest = %s, want %s", desc.Digest, want)
+	}
+}
+
+func TestPushArchiveDryRun(t *testing.T) {
+	host := newTestRegistry(t)
+	path := writeTestArchive(t, makeImageIndex(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/amd64"): imageWithPlatform(t, types.ParsePlatform("linux/amd64")),
+	}, ""))
+
+	res, err := Push(context.Background(), "oci:"+path,
+		WithLogger(NopLogger()),
+		WithRepository(host+"/app"),
+		WithDryRun(DryRunPlan),
+	)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if res.DryRun != DryRunPlan || res.Digest == "" {
+		t.Fatalf("Result = %+v, want dry run with digest", res)
+	}
+	if _, err := remote.Head(mustParseReference(t, host+"/app:latest")); err == nil {
+		t.Fatal("image was pushed during dry run")
+	}
+}
+
+func TestPushArchiveValidation(t *testing.T) {
+	tests := []struct {
+		archive string
+		options []RunOption
+		want    string
+	}{
+		{"./image.tar", []RunOption{WithRepository("example.com/app")}, `unsupported archive "./image.tar"`},
+		{"oci:./image.tar", nil, "push requires a repository"},
+		{"oci:./image.tar", []RunOption{WithRepository("example.com/app"), WithLoad(true)}, "push only supports pushing to a registry"},
+	}
+	for _, test := range tests {
+		_, err := Push(context.Background(), test.archive, test.options...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("Push(%q) error = %v, want %q", test.archive, err, test.want)
+		}
+	}
+}
+
+func writeTestArchive(t *testing.T, index v1.ImageIndex) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "image.tar")
+	if err := oci.WriteArchive(path, index); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
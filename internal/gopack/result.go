@@ -0,0 +1,214 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Result describes everything produced by a call to Run.
+type Result struct {
+	// Reference is the single preferred reference to the produced image or
+	// index. When writing to an output, it is the archive path.
+	Reference string `json:"reference"`
+	// References contains the preferred reference to the image in each
+	// repository it was pushed or loaded to.
+	References []string `json:"references,omitempty"`
+	// Repository is the first repository the image was pushed or loaded to.
+	Repository string `json:"repository,omitempty"`
+	// Repositories contains every repository the image was pushed or loaded
+	// to.
+	Repositories []string `json:"repositories,omitempty"`
+	// Tags contains the fully qualified references of every applied tag, in
+	// every repository.
+	Tags []string `json:"tags,omitempty"`
+	// Digest is the digest of the index, or of the image when only a
+	// single platform was built.
+	Digest string `json:"digest"`
+	// MediaType is the media type of the manifest identified by Digest.
+	MediaType string `json:"mediaType"`
+	// Archive is the path of the written archive, if any.
+	Archive string `json:"archive,omitempty"`
+	// DryRun is the dry run mode, if any. Nothing is written in a dry run;
+	// with DryRunPlan, image digests are also unknown.
+	DryRun string `json:"dryRun,omitempty"`
+	// SizeViolations contains every exceeded size budget when budgets only
+	// warn.
+	SizeViolations []SizeViolation `json:"sizeViolations,omitempty"`
+
+	Base      BaseResult    `json:"base,omitzero"`
+	Images    []ImageResult `json:"images"`
+	Durations Durations     `json:"durations"`
+}
+
+// PackagesResult describes everything produced by a call to RunPackages.
+type PackagesResult struct {
+	// Results contains the result of each built main package, sorted by
+	// import path.
+	Results []*Result `json:"results"`
+	// Skipped contains the import path of each main package that was not
+	// built because it is not affected by the changes since the git ref.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// BaseResult describes the resolved base image.
+type BaseResult struct {
+	Reference string `json:"reference"`
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+}
+
+// ImageResult describes a single per-platform image.
+type ImageResult struct {
+	Platform  string `json:"platform"`
+	Digest    string `json:"digest,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+	// Base is the digest of the platform's base image.
+	Base string `json:"base"`
+	// BaseReference is the platform's base image, if it overrides the
+	// shared base.
+	BaseReference string `json:"baseReference,omitempty"`
+	// Tags are the image's platform tags, as <repository>:<tag>, if
+	// enabled with WithPlatformTags.
+	Tags   []string      `json:"tags,omitempty"`
+	Layers []LayerResult `json:"layers,omitempty"`
+	// Size is the compressed size of the image, split between the base
+	// image's layers and the layers added by gopack.
+	Size ImageSize `json:"size,omitzero"`
+}
+
+// ImageSize is a breakdown of the compressed layer size of an image.
+type ImageSize struct {
+	Base  int64 `json:"base"`
+	App   int64 `json:"app"`
+	Total int64 `json:"total"`
+}
+
+// LayerResult describes a single compressed image layer.
+type LayerResult struct {
+	Digest    string `json:"digest"`
+	MediaType string `json:"mediaType"`
+	Size      int64  `json:"size"`
+}
+
+// Durations records how long each phase of Run took. The phases overlap, as
+// platforms are compiled while the base is resolved and uploaded as soon as
+// they are built: Build ends once the last image is built, and Push starts
+// with the first upload.
+type Durations struct {
+	Resolve Duration `json:"resolve"`
+	Build   Duration `json:"build"`
+	Push    Duration `json:"push"`
+	Total   Duration `json:"total"`
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string (e.g.
+// "1.5s").
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func since(t time.Time) Duration {
+	return Duration(time.Since(t))
+}
+
+func describeImages(imgs map[types.Platform]v1.Image) ([]ImageResult, error) {
+	out := make([]ImageResult, 0, len(imgs))
+	for _, platform := range sortedPlatforms(imgs) {
+		res, err := describeImage(platform, imgs[platform])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func describeImage(platform types.Platform, img v1.Image) (ImageResult, error) {
+	digest, err := img.Digest()
+	if err != nil {
+		return ImageResult{}, err
+	}
+	mt, err := img.MediaType()
+	if err != nil {
+		return ImageResult{}, err
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return ImageResult{}, err
+	}
+
+	layers := make([]LayerResult, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		layers = append(layers, LayerResult{
+			Digest:    layer.Digest.String(),
+			MediaType: string(layer.MediaType),
+			Size:      layer.Size,
+		})
+	}
+
+	return ImageResult{
+		Platform:  platform.String(),
+		Digest:    digest.String(),
+		MediaType: string(mt),
+		Layers:    layers,
+	}, nil
+}
+
+// setBaseReferences sets the base reference of each image whose platform
+// overrides the shared base.
+func setBaseReferences(images []ImageResult, opts *runOptions) {
+	for i := range images {
+		if po, ok := opts.overrides[types.ParsePlatform(images[i].Platform)]; ok && po.Base != "" {
+			images[i].BaseReference = po.Base
+		}
+	}
+}
+
+// setBaseDigests sets the base digest of each image from bases.
+func setBaseDigests(images []ImageResult, bases map[types.Platform]v1.Image) error {
+	for i := range images {
+		base, ok := bases[types.ParsePlatform(images[i].Platform)]
+		if !ok {
+			continue
+		}
+		digest, err := base.Digest()
+		if err != nil {
+			return err
+		}
+		images[i].Base = digest.String()
+	}
+	return nil
+}
@@ -15,30 +15,25 @@
 package gopack
 
 import (
-	"archive/tar"
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
+	"maps"
 	"os"
-	"path/filepath"
 	"sort"
 	"strings"
-	"sync"
+	"time"
 
-	"github.com/ryanfowler/gopack/internal/golang"
 	"github.com/ryanfowler/gopack/internal/oci"
 	"github.com/ryanfowler/gopack/internal/types"
 
+	"github.com/google/go-containerregistry/pkg/compression"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
-	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	crtypes "github.com/google/go-containerregistry/pkg/v1/types"
-	"golang.org/x/sync/errgroup"
 )
 
 var ErrNoMatchingImage = errors.New("no matching image")
@@ -46,7 +41,12 @@ var ErrNoMatchingImage = errors.New("no matching image")
 const dockerDaemon = "docker"
 const ociOutputPrefix = "oci:"
 
-func Run(ctx context.Context, options ...RunOption) (string, error) {
+// Run builds the Go binary for every requested platform, packages each as an
+// image on top of the base, and pushes, loads, or writes the result. If a size
+// budget fails, the result of the build is returned with the
+// *SizeBudgetError, and nothing is pushed, loaded, or written.
+func Run(ctx context.Context, options ...RunOption) (*Result, error) {
+	start := time.Now()
 	opts := defaultRunOptions()
 	for _, o := range options {
 		o(opts)
@@ -55,45 +55,148 @@ func Run(ctx context.Context, options ...RunOption) (string, error) {
 		opts.daemon = dockerDaemon
 	}
 	if err := validateDestination(opts); err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := validateDryRun(opts.dryRun); err != nil {
+		return nil, err
+	}
+	if err := validateSizeBudgets(opts); err != nil {
+		return nil, err
+	}
+	if err := validateLayerCompression(opts.layerCompression); err != nil {
+		return nil, err
+	}
+	if opts.retries < 0 {
+		return nil, fmt.Errorf("invalid retries %d: must not be negative", opts.retries)
+	}
+	if err := oci.ValidateTagPatterns(opts.immutableTags); err != nil {
+		return nil, err
+	}
+	tags, err := resolveTags(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.tags = tags
+	if err := parsePlatformOptions(opts); err != nil {
+		return nil, err
+	}
+	if err := validateBuilder(opts); err != nil {
+		return nil, err
 	}
 	platforms, err := parsePlatforms(opts.platforms)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := checkPlatforms(opts, platforms); err != nil {
+		return nil, err
+	}
+	if opts.daemon != "" && len(platforms) != 1 {
+		return nil, errors.New("push: can only push a single image to docker")
+	}
+	if opts.platformTags {
+		if err := validatePlatformTags(opts.tags, platforms); err != nil {
+			return nil, err
+		}
 	}
 
 	// binName represents the name of the application/binary, as parsed from
 	// the provided main path. If no repository is provided, the binName is
 	// used.
-	binName, err := parseBinName(opts.mainPath)
+	binName, cleanup, err := resolveMain(ctx, opts)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if opts.repository == "" {
-		opts.repository = binName
+	defer cleanup()
+	if err := setBuilder(opts); err != nil {
+		return nil, err
+	}
+	if len(opts.repositories) == 0 {
+		opts.repositories = []string{binName}
 	}
 
-	baseDesc, err := getBaseDesc(ctx, opts)
-	if err != nil {
-		return "", err
+	res := &Result{DryRun: opts.dryRun}
+	if err := setDestinations(res, opts); err != nil {
+		return nil, err
 	}
 
-	baseImgs, err := matchImages(platforms, baseDesc)
-	if err != nil {
-		return "", err
+	if opts.dryRun == DryRunPlan {
+		phase := time.Now()
+		bases, err := resolveBases(ctx, platforms, opts)
+		if err != nil {
+			return nil, err
+		}
+		res.Base = baseResult(opts.base, bases.desc)
+		res.Durations.Resolve = since(phase)
+		if res.Images, err = describeBases(bases.imgs); err != nil {
+			return nil, err
+		}
+		setBaseReferences(res.Images, opts)
+		setPlatformTags(res.Images, opts)
+		setPlannedReferences(res, opts)
+		res.Durations.Total = since(start)
+		return res, nil
 	}
 
-	imgs, err := buildAllPlatforms(ctx, baseImgs, binName, opts)
+	progress := newProgress(opts.logger)
+	defer progress.Close()
+
+	dir, err := os.MkdirTemp("", "gopack-")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer os.RemoveAll(dir)
 
-	output, err := push(ctx, imgs, baseDesc.MediaType, opts)
+	p, err := buildPipeline(ctx, dir, platforms, binName, opts, progress)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	bases, imgs := p.bases, p.imgs
+	res.Base = baseResult(opts.base, bases.desc)
+	res.Durations.Resolve = Duration(p.resolve)
+	res.Durations.Build = Duration(p.build)
+
+	if res.Images, err = describeImages(imgs); err != nil {
+		return nil, err
+	}
+	if err := setBaseDigests(res.Images, bases.imgs); err != nil {
+		return nil, err
+	}
+	setBaseReferences(res.Images, opts)
+	setPlatformTags(res.Images, opts)
+	if err := setSizes(res.Images, bases.imgs); err != nil {
+		return nil, err
+	}
+	if res.SizeViolations, err = checkSizeBudgets(res.Images, opts); err != nil {
+		// The sizes show which images and layers exceed their budget.
+		res.Durations.Total = since(start)
+		return res, err
+	}
+
+	if opts.dryRun == DryRunBuild {
+		out := finalManifest(imgs, bases.mediaType, opts)
+		if opts.output != "" {
+			err = setDigest(res, out)
+		} else {
+			err = setOutput(res, opts.repositories, out, opts.tags)
+		}
+		if err != nil {
+			return nil, err
+		}
+		res.Durations.Total = since(start)
+		return res, nil
 	}
 
-	return output, nil
+	phase := time.Now()
+	if err = push(ctx, imgs, bases.mediaType, p.uploaded, opts, progress, res); err != nil {
+		return nil, err
+	}
+	if !p.uploadStart.IsZero() {
+		phase = p.uploadStart
+	}
+	res.Durations.Push = since(phase)
+	res.Durations.Total = since(start)
+
+	return res, nil
 }
 
 func validateDestination(opts *runOptions) error {
@@ -108,9 +211,21 @@ func validateDestination(opts *runOptions) error {
 			return err
 		}
 	}
+	if opts.platformTags && (opts.output != "" || opts.daemon != "" || opts.load) {
+		return errors.New("platform tags require pushing to a registry")
+	}
 	return nil
 }
 
+func validateLayerCompression(v string) error {
+	switch compression.Compression(v) {
+	case compression.GZip, compression.ZStd, compression.None:
+		return nil
+	default:
+		return fmt.Errorf("unsupported layer compression %q (supported: %s, %s, %s)", v, compression.GZip, compression.ZStd, compression.None)
+	}
+}
+
 func validateDaemon(daemon string) error {
 	switch daemon {
 	case "", dockerDaemon:
@@ -120,136 +235,163 @@ func validateDaemon(daemon string) error {
 	}
 }
 
-func parseBinName(mainPath string) (string, error) {
-	mainPath, err := filepath.Abs(mainPath)
-	if err != nil {
-		return "", err
-	}
-	stat, err := os.Stat(mainPath)
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSuffix(stat.Name(), filepath.Ext(stat.Name())), nil
-}
-
-func buildAllPlatforms(ctx context.Context, imgs map[types.Platform]v1.Image, binName string, opts *runOptions) (map[types.Platform]v1.Image, error) {
-	if len(opts.platforms) == 1 {
-		opts.logger.Printf("Building image for platform %s\n", opts.platforms[0])
-	} else {
-		opts.logger.Printf("Building images for platforms %v\n", opts.platforms)
-	}
-
-	goBuilder := newGoBuilder(opts)
-	semaphore := make(chan struct{}, opts.concurrency)
-
-	var mu sync.Mutex
-	out := make(map[types.Platform]v1.Image, len(imgs))
-
-	eg, ctx := errgroup.WithContext(ctx)
-	for platform, img := range imgs {
-		select {
-		case semaphore <- struct{}{}:
-		case <-ctx.Done():
-		}
-		if ctx.Err() != nil {
-			break
+func push(ctx context.Context, imgs map[types.Platform]v1.Image, mt crtypes.MediaType, uploaded bool, opts *runOptions, progress types.Progress, res *Result) error {
+	out := finalManifest(imgs, mt, opts)
+	if opts.output != "" {
+		index := out.(v1.ImageIndex)
+		if err := oci.WriteArchive(res.Archive, index); err != nil {
+			return err
 		}
+		return setDigest(res, index)
+	}
 
-		platform := platform
-		inImg := img
-		eg.Go(func() error {
-			defer func() { <-semaphore }()
-			outImg, err := build(ctx, goBuilder, binName, platform, inImg, opts)
+	if opts.daemon == dockerDaemon {
+		img := out.(v1.Image)
+		for _, repo := range opts.repositories {
+			err := oci.PushDaemon(ctx, repo, img, oci.WithTags(opts.tags), oci.WithLogger(opts.logger))
 			if err != nil {
-				return fmt.Errorf("building %s: %w", platform, err)
+				return err
 			}
-			mu.Lock()
-			out[platform] = outImg
-			mu.Unlock()
-			return nil
-		})
+		}
+		return setOutput(res, opts.repositories, img, opts.tags)
 	}
 
-	if err := eg.Wait(); err != nil {
-		return nil, err
-	}
-	if ctx.Err() != nil {
-		return nil, ctx.Err()
+	if err := pushRegistries(ctx, imgs, out, uploaded, opts, progress); err != nil {
+		return err
 	}
-
-	return out, nil
+	return setOutput(res, opts.repositories, out, opts.tags)
 }
 
-func build(ctx context.Context, goBuilder *golang.GoBuilder, binName string, p types.Platform, img v1.Image, opts *runOptions) (v1.Image, error) {
-	dir, err := os.MkdirTemp("", "gopack-")
+// pushRegistries pushes out, which is built from imgs, to every configured
+// repository. If the images were already uploaded, only the blobs of out
+// itself are reported as progress.
+func pushRegistries(ctx context.Context, imgs map[types.Platform]v1.Image, out manifest, uploaded bool, opts *runOptions, progress types.Progress) error {
+	repos, err := parseRepositories(opts.repositories)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer os.RemoveAll(dir)
 
-	goBinPath := filepath.Join(dir, binName)
-	err = goBuilder.GoBuild(ctx, goBinPath, p)
-	if err != nil {
-		return nil, err
+	var tasks map[string]string
+	if !uploaded {
+		if tasks, err = blobTasks(imgs); err != nil {
+			return err
+		}
 	}
 
-	buildOptions := []oci.BuildOption{
-		oci.WithCompressionLevel(opts.compressionLevel),
-		oci.WithLabels(opts.labels),
+	if opts.platformTags {
+		if err := checkPlatformTags(ctx, repos, imgs, opts); err != nil {
+			return err
+		}
+	}
+	err = oci.PushAll(ctx, repos, out,
+		oci.WithCreateRepository(opts.createRepository),
+		oci.WithImmutableTags(opts.immutableTags),
+		oci.WithSkipIfExists(opts.skipIfExists),
+		oci.WithTags(opts.tags),
+		oci.WithLogger(opts.logger),
+		oci.WithProgress(progress, tasks),
+		oci.WithRegistryOptions(opts.registryOptions()...))
+	if err != nil {
+		return err
+	}
+	if opts.platformTags {
+		if err := pushPlatformTags(ctx, repos, imgs, opts); err != nil {
+			return err
+		}
+	}
+	for platform := range imgs {
+		progress.Phase(platform.String(), "pushed")
 	}
-	return oci.BuildImage(ctx, goBinPath, img, buildOptions...)
+	return nil
 }
 
-func push(ctx context.Context, imgs map[types.Platform]v1.Image, mt crtypes.MediaType, opts *runOptions) (string, error) {
-	if opts.output != "" {
-		path, err := parseOutput(opts.output)
+// checkPlatformTags fails if an immutable platform tag already points at a
+// different image, so that nothing is pushed, not even the index.
+func checkPlatformTags(ctx context.Context, repos []name.Repository, imgs map[types.Platform]v1.Image, opts *runOptions) error {
+	for _, platform := range sortedPlatforms(imgs) {
+		if !platform.IsSupported() {
+			continue
+		}
+		err := oci.CheckImmutableTags(ctx, repos, imgs[platform],
+			oci.WithImmutableTags(opts.immutableTags),
+			oci.WithTags(platformTags(opts.tags, platform)),
+			oci.WithRegistryOptions(opts.registryOptions()...))
 		if err != nil {
-			return "", err
+			return fmt.Errorf("platform tags for %s: %w", platform, err)
 		}
-		if err := writeOCIArchive(path, imgs); err != nil {
-			return "", err
-		}
-		return path, nil
 	}
+	return nil
+}
 
-	if opts.daemon == dockerDaemon {
-		if len(imgs) != 1 {
-			return "", errors.New("push: can only push a single image to docker")
-		}
-		var img v1.Image
-		for _, i := range imgs {
-			img = i
-		}
-		err := oci.PushDaemon(ctx, opts.repository, img, oci.WithTags(opts.tags), oci.WithLogger(opts.logger))
+// pushPlatformTags applies the platform tags of every image, which were
+// already pushed as part of the index. Immutable platform tags were checked
+// by checkPlatformTags before the index was pushed. With skip-if-exists, the
+// tags of an image that already existed are left unchanged.
+func pushPlatformTags(ctx context.Context, repos []name.Repository, imgs map[types.Platform]v1.Image, opts *runOptions) error {
+	for _, platform := range sortedPlatforms(imgs) {
+		if !platform.IsSupported() {
+			continue
+		}
+		err := oci.PushAll(ctx, repos, imgs[platform],
+			oci.WithSkipIfExists(opts.skipIfExists),
+			oci.WithTags(platformTags(opts.tags, platform)),
+			oci.WithLogger(opts.logger),
+			oci.WithRegistryOptions(opts.registryOptions()...))
 		if err != nil {
-			return "", err
+			return fmt.Errorf("platform tags for %s: %w", platform, err)
 		}
-		return chooseOutput(opts.repository, img, opts.tags)
 	}
+	return nil
+}
 
-	repo, err := name.NewRepository(opts.repository)
-	if err != nil {
-		return "", fmt.Errorf("push: parsing repository %q: %w", opts.repository, err)
+// finalManifest returns what is written to the destination: an OCI index for
+// archives, the single image for daemons, and otherwise the single image or
+// an index of every platform.
+func finalManifest(imgs map[types.Platform]v1.Image, mt crtypes.MediaType, opts *runOptions) manifest {
+	switch {
+	case opts.output != "":
+		return makeImageIndex(imgs, crtypes.OCIImageIndex)
+	case opts.daemon != "", len(imgs) == 1:
+		return singleImage(imgs)
+	default:
+		if opts.layerCompression != string(compression.GZip) {
+			// The images are OCI manifests, which Docker manifest lists
+			// cannot reference.
+			mt = crtypes.OCIImageIndex
+		}
+		return makeImageIndex(imgs, mt)
 	}
+}
 
-	if len(imgs) == 1 {
-		var img v1.Image
-		for _, i := range imgs {
-			img = i
-		}
-		err = oci.Push(ctx, repo, img, oci.WithTags(opts.tags), oci.WithLogger(opts.logger))
+// setDestinations records where the image will be written.
+func setDestinations(res *Result, opts *runOptions) error {
+	if opts.output != "" {
+		path, err := parseOutput(opts.output)
 		if err != nil {
-			return "", err
+			return err
 		}
-		return chooseOutput(opts.repository, img, opts.tags)
+		res.Reference = path
+		res.Archive = path
+		return nil
 	}
 
-	index := makeImageIndex(imgs, mt)
-	err = oci.Push(ctx, repo, index, oci.WithTags(opts.tags), oci.WithLogger(opts.logger))
-	if err != nil {
-		return "", err
+	res.Repository = opts.repositories[0]
+	res.Repositories = opts.repositories
+	res.Tags = make([]string, 0, len(opts.repositories)*len(opts.tags))
+	for _, repo := range opts.repositories {
+		for _, tag := range opts.tags {
+			res.Tags = append(res.Tags, repo+":"+tag)
+		}
 	}
-	return chooseOutput(opts.repository, index, opts.tags)
+	return nil
+}
+
+func singleImage(imgs map[types.Platform]v1.Image) v1.Image {
+	var img v1.Image
+	for _, i := range imgs {
+		img = i
+	}
+	return img
 }
 
 func parseOutput(output string) (string, error) {
@@ -260,34 +402,13 @@ func parseOutput(output string) (string, error) {
 	return path, nil
 }
 
-func writeOCIArchive(path string, imgs map[types.Platform]v1.Image) error {
-	dir, err := os.MkdirTemp("", "gopack-oci-")
-	if err != nil {
-		return err
-	}
-	defer os.RemoveAll(dir)
-
-	if _, err := layout.Write(dir, makeImageIndex(imgs, crtypes.OCIImageIndex)); err != nil {
-		return fmt.Errorf("writing OCI layout: %w", err)
-	}
-	return tarDirectory(path, dir)
-}
-
 func makeImageIndex(imgs map[types.Platform]v1.Image, mt crtypes.MediaType) v1.ImageIndex {
 	if !mt.IsIndex() {
 		mt = crtypes.OCIImageIndex
 	}
 
-	platforms := make([]types.Platform, 0, len(imgs))
-	for platform := range imgs {
-		platforms = append(platforms, platform)
-	}
-	sort.Slice(platforms, func(i, j int) bool {
-		return platforms[i].String() < platforms[j].String()
-	})
-
 	addendums := make([]mutate.IndexAddendum, 0, len(imgs))
-	for _, platform := range platforms {
+	for _, platform := range sortedPlatforms(imgs) {
 		addendums = append(addendums, mutate.IndexAddendum{
 			Add: imgs[platform],
 			Descriptor: v1.Descriptor{
@@ -304,102 +425,149 @@ func makeImageIndex(imgs map[types.Platform]v1.Image, mt crtypes.MediaType) v1.I
 	return mutate.AppendManifests(base, addendums...)
 }
 
-func tarDirectory(path string, dir string) (err error) {
-	out, err := os.Create(path)
-	if err != nil {
-		return err
+func sortedPlatforms[V any](m map[types.Platform]V) []types.Platform {
+	platforms := make([]types.Platform, 0, len(m))
+	for platform := range m {
+		platforms = append(platforms, platform)
 	}
-	defer func() {
-		if closeErr := out.Close(); err == nil {
-			err = closeErr
-		}
-	}()
+	sort.Slice(platforms, func(i, j int) bool {
+		return platforms[i].String() < platforms[j].String()
+	})
+	return platforms
+}
 
-	tw := tar.NewWriter(out)
-	defer func() {
-		if closeErr := tw.Close(); err == nil {
-			err = closeErr
-		}
-	}()
+type digester interface {
+	Digest() (v1.Hash, error)
+}
 
-	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		rel, err := filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
-		if rel == "." {
-			return nil
-		}
+// manifest is implemented by both v1.Image and v1.ImageIndex.
+type manifest interface {
+	remote.Taggable
+	digester
+	MediaType() (crtypes.MediaType, error)
+}
 
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
-		hdr, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
-		}
-		hdr.Name = filepath.ToSlash(rel)
-		if d.IsDir() {
-			hdr.Name += "/"
-		}
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
-		if !info.Mode().IsRegular() {
-			return nil
-		}
+func setDigest(res *Result, m manifest) error {
+	digest, err := m.Digest()
+	if err != nil {
+		return err
+	}
+	mt, err := m.MediaType()
+	if err != nil {
+		return err
+	}
+	res.Digest = digest.String()
+	res.MediaType = string(mt)
+	return nil
+}
 
-		in, err := os.Open(path)
+func setOutput(res *Result, repos []string, m manifest, tags []string) error {
+	if err := setDigest(res, m); err != nil {
+		return err
+	}
+	res.References = make([]string, 0, len(repos))
+	for _, repo := range repos {
+		out, err := chooseOutput(repo, m, tags)
 		if err != nil {
 			return err
 		}
-		_, copyErr := io.Copy(tw, in)
-		closeErr := in.Close()
-		if copyErr != nil {
-			return copyErr
-		}
-		return closeErr
-	})
-	return err
+		res.References = append(res.References, out)
+	}
+	res.Reference = res.References[0]
+	return nil
 }
 
-type digester interface {
-	Digest() (v1.Hash, error)
+func chooseOutput(repo string, img digester, tags []string) (string, error) {
+	if tag, ok := preferredTag(tags); ok {
+		return fmt.Sprintf("%s:%s", repo, tag), nil
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s@%s", repo, digest), nil
 }
 
-func chooseOutput(repo string, img digester, tags []string) (string, error) {
-	var chosen string
+// preferredTag returns the first tag other than the default tag.
+func preferredTag(tags []string) (string, bool) {
 	for _, tag := range tags {
 		if tag != oci.DefaultTag {
-			chosen = tag
-			break
+			return tag, true
 		}
 	}
-	if chosen != "" {
-		return fmt.Sprintf("%s:%s", repo, chosen), nil
+	return "", false
+}
+
+// baseResult describes the shared base, which is not resolved if every
+// platform overrides it.
+func baseResult(base string, desc *remote.Descriptor) BaseResult {
+	if desc == nil {
+		return BaseResult{}
+	}
+	return BaseResult{
+		Reference: base,
+		Digest:    desc.Digest.String(),
+		MediaType: string(desc.MediaType),
 	}
+}
 
-	digest, err := img.Digest()
-	if err != nil {
-		return "", err
+// bases are the base images of every platform: the shared base, unless it is
+// overridden for the platform.
+type bases struct {
+	// desc is the shared base, or nil if every platform overrides it.
+	desc *remote.Descriptor
+	// mediaType is the media type of the shared base or, if it was not
+	// resolved, of the first overriding base.
+	mediaType crtypes.MediaType
+	imgs      map[types.Platform]v1.Image
+}
+
+// resolveBases fetches every base image used and matches each platform to
+// its image.
+func resolveBases(ctx context.Context, platforms []types.Platform, opts *runOptions) (*bases, error) {
+	var refs []string
+	groups := make(map[string][]types.Platform)
+	for _, platform := range platforms {
+		ref := opts.baseFor(platform)
+		if _, ok := groups[ref]; !ok {
+			refs = append(refs, ref)
+		}
+		groups[ref] = append(groups[ref], platform)
 	}
-	return fmt.Sprintf("%s@%s", repo, digest), nil
+
+	out := &bases{imgs: make(map[types.Platform]v1.Image, len(platforms))}
+	for _, ref := range refs {
+		desc, err := getBaseDesc(ctx, ref, opts)
+		if err != nil {
+			return nil, err
+		}
+		imgs, err := matchImages(groups[ref], desc)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(out.imgs, imgs)
+		if ref == opts.base {
+			out.desc = desc
+		}
+		if out.mediaType == "" || ref == opts.base {
+			out.mediaType = desc.MediaType
+		}
+	}
+	return out, nil
 }
 
-func getBaseDesc(ctx context.Context, opts *runOptions) (*remote.Descriptor, error) {
-	opts.logger.Printf("Fetching manifest for base: %s\n", opts.base)
-	baseRef, err := name.ParseReference(opts.base)
+func getBaseDesc(ctx context.Context, base string, opts *runOptions) (*remote.Descriptor, error) {
+	opts.logger.Printf("Fetching manifest for base: %s\n", base)
+	baseRef, err := name.ParseReference(base)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse base: %w", err)
 	}
-	desc, err := oci.Get(ctx, baseRef)
+	desc, err := oci.Get(ctx, baseRef, opts.registryOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch base: %w", err)
 	}
+	opts.logger.Debugf("Resolved base %s to %s (%s)\n", base, desc.Digest, desc.MediaType)
 	return desc, nil
 }
 
@@ -476,22 +644,3 @@ func platformsEqual(p1 types.Platform, p2 *v1.Platform) bool {
 	}
 	return p1.OS() == p2.OS && p1.Arch() == p2.Architecture && p1.Variant() == p2.Variant
 }
-
-func newGoBuilder(opts *runOptions) *golang.GoBuilder {
-	goOptions := []golang.Option{
-		golang.WithCGOEnabled(opts.cgoEnabled),
-		golang.WithTrimpath(opts.trimpathEnabled),
-	}
-
-	if opts.ldflags != "" {
-		goOptions = append(goOptions, golang.WithLDFlags(opts.ldflags))
-	}
-	if opts.mainPath != "" {
-		goOptions = append(goOptions, golang.WithMainPath(opts.mainPath))
-	}
-	if opts.modFlag != "" {
-		goOptions = append(goOptions, golang.WithModFlag(opts.modFlag))
-	}
-
-	return golang.New(goOptions...)
-}
@@ -15,7 +15,7 @@
 package gopack
 
 import (
-	"archive/tar"
+	"bytes"
 	"context"
 	"errors"
 	"io"
@@ -24,9 +24,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/ryanfowler/gopack/internal/oci"
 	"github.com/ryanfowler/gopack/internal/types"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -35,6 +37,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	crtypes "github.com/google/go-containerregistry/pkg/v1/types"
 )
 
 func TestRunRejectsUnsupportedDaemonBeforeOtherWork(t *testing.T) {
@@ -90,6 +93,306 @@ func TestRunRejectsOutputWithDaemonLoadBeforeOtherWork(t *testing.T) {
 	}
 }
 
+func TestRunPushesToRegistry(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+
+	var logs bytes.Buffer
+	res, err := Run(context.Background(),
+		WithLogger(NewTextLogger(&logs, types.LevelDebug)),
+		WithBase(host+"/base:latest"),
+		WithMainPath(writeTestMain(t)),
+		WithRepository(host+"/app"),
+		WithTags([]string{"v1", "latest"}),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, want := res.Reference, host+"/app:v1"; got != want {
+		t.Fatalf("Result.Reference = %q, want %q", got, want)
+	}
+	if len(res.Tags) != 2 || len(res.Images) != 1 {
+		t.Fatalf("Result = %+v, want 2 tags and 1 image", res)
+	}
+	if res.Digest != res.Images[0].Digest {
+		t.Fatalf("Result.Digest = %s, want image digest %s", res.Digest, res.Images[0].Digest)
+	}
+
+	desc, err := remote.Get(mustParseReference(t, host+"/app:latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest.String() != res.Digest {
+		t.Fatalf("pushed digest = %s, want %s", desc.Digest, res.Digest)
+	}
+
+	for _, want := range []string{
+		"linux/amd64: compiling\n",
+		"linux/amd64: uploaded blob " + res.Images[0].Layers[1].Digest + "\n",
+		"linux/amd64: pushed\n",
+		host + "/app: pushed\n",
+		"Tagged " + host + "/app:v1\n",
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	host := newTestRegistry(t)
+	base := imageWithPlatform(t, types.ParsePlatform("linux/amd64"))
+	writeTestImage(t, host+"/base:latest", base)
+	baseDigest, err := base.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mainPath := writeTestMain(t)
+
+	for _, mode := range []string{DryRunPlan, DryRunBuild} {
+		res, err := Run(context.Background(),
+			WithLogger(NopLogger()),
+			WithBase(host+"/base:latest"),
+			WithMainPath(mainPath),
+			WithRepositories([]string{host + "/app", host + "/dr"}),
+			WithTags([]string{"latest", "v1"}),
+			WithDryRun(mode),
+		)
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", mode, err)
+		}
+		if res.DryRun != mode {
+			t.Fatalf("%s: Result.DryRun = %q", mode, res.DryRun)
+		}
+		if len(res.Tags) != 4 || len(res.References) != 2 || res.References[1] != host+"/dr:v1" {
+			t.Fatalf("%s: Result destinations = %v, %v", mode, res.Tags, res.References)
+		}
+		if len(res.Images) != 1 || res.Images[0].Base != baseDigest.String() {
+			t.Fatalf("%s: Result.Images = %+v, want base %s", mode, res.Images, baseDigest)
+		}
+		if built := res.Images[0].Digest != ""; built != (mode == DryRunBuild) {
+			t.Fatalf("%s: image digest = %q", mode, res.Images[0].Digest)
+		}
+		if _, err := remote.Head(mustParseReference(t, host+"/app:v1")); err == nil {
+			t.Fatalf("%s: image was pushed during dry run", mode)
+		}
+	}
+}
+
+func TestRunPlatformOptions(t *testing.T) {
+	host := newTestRegistry(t)
+	amd64, arm64 := types.ParsePlatform("linux/amd64"), types.ParsePlatform("linux/arm64")
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, amd64))
+	armBase := imageWithPlatform(t, arm64)
+	writeTestImage(t, host+"/arm-base:latest", armBase)
+	armDigest, err := armBase.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := builderFunc(func(ctx context.Context, outPath string, platform types.Platform) error {
+		return os.WriteFile(outPath, []byte(platform.String()), 0o755)
+	})
+	for _, mode := range []string{DryRunPlan, DryRunBuild} {
+		res, err := Run(context.Background(),
+			WithLogger(NopLogger()),
+			WithBase(host+"/base:latest"),
+			WithBuilder(builder),
+			WithMainPath("example.com/app"),
+			WithRepository(host+"/app"),
+			WithPlatforms([]string{"linux/amd64", "linux/arm64"}),
+			WithPlatformOptions(map[string]PlatformOptions{
+				"linux/arm64": {Base: host + "/arm-base:latest"},
+			}),
+			WithDryRun(mode),
+		)
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", mode, err)
+		}
+		if res.Base.Reference != host+"/base:latest" || len(res.Images) != 2 {
+			t.Fatalf("%s: Result = %+v, want shared base and 2 images", mode, res)
+		}
+		if img := res.Images[0]; img.Platform != "linux/amd64" || img.BaseReference != "" {
+			t.Fatalf("%s: Result.Images[0] = %+v, want shared base", mode, img)
+		}
+		if img := res.Images[1]; img.BaseReference != host+"/arm-base:latest" || img.Base != armDigest.String() {
+			t.Fatalf("%s: Result.Images[1] = %+v, want overridden base %s", mode, img, armDigest)
+		}
+	}
+}
+
+func TestRunRejectsInvalidPlatformOptions(t *testing.T) {
+	tests := []struct {
+		options []RunOption
+		want    string
+	}{
+		{[]RunOption{WithPlatformOptions(map[string]PlatformOptions{"linux/ad64": {Base: "base"}})}, `unsupported platform "linux/ad64"`},
+		{[]RunOption{WithPlatformOptions(map[string]PlatformOptions{"linux/arm64": {Base: "base"}})}, "linux/arm64 is not one of the platforms built"},
+		{[]RunOption{WithPlatformOptions(map[string]PlatformOptions{"linux/amd64": {Env: []string{"GOARCH=arm64"}}})}, "GOARCH is set from the platform"},
+		{[]RunOption{WithEnv([]string{"CC"})}, "must be KEY=VALUE"},
+		{[]RunOption{WithEnv([]string{"CC=clang"}), WithBuilder(&fakeBuilder{})}, "cannot set the build environment with prebuilt binaries or a custom builder"},
+		{[]RunOption{WithPlatformOptions(map[string]PlatformOptions{"linux/amd64": {LDFlags: "-s"}}), WithBuilder(&fakeBuilder{})}, "cannot override build options"},
+		{[]RunOption{WithCompiler(CompilerTinyGo), WithPlatformOptions(map[string]PlatformOptions{"linux/amd64": {LDFlags: "-linkmode external"}})}, `linux/amd64: tinygo does not support ldflag "-linkmode"`},
+	}
+	for _, test := range tests {
+		options := append(test.options, WithMainPath("/path/that/does/not/exist"))
+		_, err := Run(context.Background(), options...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("Run() error = %v, want %q", err, test.want)
+		}
+	}
+}
+
+func TestRunPlatformTags(t *testing.T) {
+	host := newTestRegistry(t)
+	amd64, armv7 := types.ParsePlatform("linux/amd64"), types.ParsePlatform("linux/arm/v7")
+	base := makeImageIndex(map[types.Platform]v1.Image{
+		amd64: imageWithPlatform(t, amd64),
+		armv7: imageWithPlatform(t, armv7),
+	}, "")
+	if err := remote.WriteIndex(mustParseReference(t, host+"/base:latest"), base); err != nil {
+		t.Fatal(err)
+	}
+
+	builder := builderFunc(func(ctx context.Context, outPath string, platform types.Platform) error {
+		return os.WriteFile(outPath, []byte(platform.String()), 0o755)
+	})
+	res, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithBuilder(builder),
+		WithMainPath("example.com/app"),
+		WithRepository(host+"/app"),
+		WithPlatforms([]string{"linux/amd64", "linux/arm/v7"}),
+		WithTags([]string{"v1", "latest"}),
+		WithPlatformTags(true),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	desc, err := remote.Head(mustParseReference(t, host+"/app:v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest.String() != res.Digest || !desc.MediaType.IsIndex() {
+		t.Fatalf("pushed %s (%s), want index %s", desc.Digest, desc.MediaType, res.Digest)
+	}
+	for _, img := range res.Images {
+		suffix := "-" + strings.ReplaceAll(img.Platform, "/", "-")
+		want := []string{host + "/app:v1" + suffix, host + "/app:latest" + suffix}
+		if !slices.Equal(img.Tags, want) {
+			t.Fatalf("%s: Tags = %v, want %v", img.Platform, img.Tags, want)
+		}
+		for _, tag := range img.Tags {
+			desc, err := remote.Head(mustParseReference(t, tag))
+			if err != nil {
+				t.Fatalf("%s: %v", tag, err)
+			}
+			if desc.Digest.String() != img.Digest {
+				t.Fatalf("%s points at %s, want %s", tag, desc.Digest, img.Digest)
+			}
+		}
+	}
+}
+
+func TestRunImmutablePlatformTags(t *testing.T) {
+	host := newTestRegistry(t)
+	amd64 := types.ParsePlatform("linux/amd64")
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, amd64))
+	existing := imageWithPlatform(t, amd64)
+	writeTestImage(t, host+"/app:v1-linux-amd64", existing)
+
+	_, err := Run(context.Background(),
+		WithLogger(NopLogger()),
+		WithBase(host+"/base:latest"),
+		WithBuilder(&fakeBuilder{}),
+		WithMainPath("example.com/app"),
+		WithRepository(host+"/app"),
+		WithTags([]string{"v1", "latest"}),
+		WithImmutableTags([]string{"v*"}),
+		WithPlatformTags(true),
+	)
+	var immErr *oci.ImmutableTagError
+	if !errors.As(err, &immErr) || immErr.Tag != host+"/app:v1-linux-amd64" {
+		t.Fatalf("Run() error = %v, want immutable platform tag error", err)
+	}
+	for _, tag := range []string{"v1", "latest", "latest-linux-amd64"} {
+		if _, err := remote.Head(mustParseReference(t, host+"/app:"+tag)); err == nil {
+			t.Fatalf("tag %s was pushed despite the immutable platform tag", tag)
+		}
+	}
+}
+
+func TestRunRejectsInvalidPlatformTags(t *testing.T) {
+	tests := []struct {
+		options []RunOption
+		want    string
+	}{
+		{[]RunOption{WithOutput("oci:./image.tar")}, "platform tags require pushing to a registry"},
+		{[]RunOption{WithLoad(true)}, "platform tags require pushing to a registry"},
+		{[]RunOption{WithTags([]string{strings.Repeat("v", 120)})}, "invalid platform tag"},
+	}
+	for _, test := range tests {
+		options := append(test.options, WithPlatformTags(true), WithMainPath("/path/that/does/not/exist"))
+		_, err := Run(context.Background(), options...)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("Run() error = %v, want %q", err, test.want)
+		}
+	}
+}
+
+func TestRunLayerCompression(t *testing.T) {
+	host := newTestRegistry(t)
+	writeTestImage(t, host+"/base:latest", imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
+	mainPath := writeTestMain(t)
+
+	tests := []struct {
+		compression string
+		manifest    crtypes.MediaType
+		layer       crtypes.MediaType
+	}{
+		{"gzip", crtypes.DockerManifestSchema2, crtypes.DockerLayer},
+		{"zstd", crtypes.OCIManifestSchema1, crtypes.OCILayerZStd},
+		{"none", crtypes.OCIManifestSchema1, crtypes.OCIUncompressedLayer},
+	}
+	for _, test := range tests {
+		res, err := Run(context.Background(),
+			WithLogger(NopLogger()),
+			WithBase(host+"/base:latest"),
+			WithMainPath(mainPath),
+			WithRepository(host+"/app"),
+			WithDryRun(DryRunBuild),
+			WithLayerCompression(test.compression),
+		)
+		if err != nil {
+			t.Fatalf("%s: Run() error = %v", test.compression, err)
+		}
+		img := res.Images[0]
+		if img.MediaType != string(test.manifest) {
+			t.Fatalf("%s: manifest media type = %s, want %s", test.compression, img.MediaType, test.manifest)
+		}
+		if layer := img.Layers[len(img.Layers)-1]; layer.MediaType != string(test.layer) {
+			t.Fatalf("%s: layer media type = %s, want %s", test.compression, layer.MediaType, test.layer)
+		}
+	}
+
+	_, err := Run(context.Background(), WithLayerCompression("brotli"), WithMainPath(mainPath))
+	if err == nil || !strings.Contains(err.Error(), `unsupported layer compression "brotli"`) {
+		t.Fatalf("Run() error = %v, want unsupported layer compression error", err)
+	}
+}
+
+func TestRunRejectsUnsupportedDryRun(t *testing.T) {
+	_, err := Run(context.Background(),
+		WithDryRun("maybe"),
+		WithMainPath("/path/that/does/not/exist"),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unsupported dry run mode "maybe"`) {
+		t.Fatalf("Run() error = %v, want unsupported dry run error", err)
+	}
+}
+
 func TestMatchImagesUsesConfigPlatformForImageManifest(t *testing.T) {
 	desc := pushImageManifest(t, imageWithPlatform(t, types.ParsePlatform("linux/amd64")))
 	if desc.Platform != nil {
@@ -117,43 +420,38 @@ func TestMatchImagesRejectsImageManifestConfigPlatformMismatch(t *testing.T) {
 	}
 }
 
-func TestWriteOCIArchive(t *testing.T) {
-	img, err := random.Image(1024, 1)
+func TestDescribeImages(t *testing.T) {
+	amd64, err := random.Image(1024, 2)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	path := filepath.Join(t.TempDir(), "image.tar")
-	imgs := map[types.Platform]v1.Image{
-		types.ParsePlatform("linux/amd64"): img,
-	}
-	if err := writeOCIArchive(path, imgs); err != nil {
+	arm64, err := random.Image(1024, 1)
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	f, err := os.Open(path)
+	res, err := describeImages(map[types.Platform]v1.Image{
+		types.ParsePlatform("linux/arm64"): arm64,
+		types.ParsePlatform("linux/amd64"): amd64,
+	})
+	if err != nil {
+		t.Fatalf("describeImages() error = %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("describeImages() returned %d images, want 2", len(res))
+	}
+	if res[0].Platform != "linux/amd64" || res[1].Platform != "linux/arm64" {
+		t.Fatalf("describeImages() platforms = %s, %s, want sorted", res[0].Platform, res[1].Platform)
+	}
+	digest, err := amd64.Digest()
 	if err != nil {
 		t.Fatal(err)
 	}
-	defer f.Close()
-
-	seen := map[string]bool{}
-	tr := tar.NewReader(f)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			t.Fatal(err)
-		}
-		seen[hdr.Name] = true
+	if res[0].Digest != digest.String() {
+		t.Fatalf("describeImages() digest = %s, want %s", res[0].Digest, digest)
 	}
-
-	for _, name := range []string{"oci-layout", "index.json"} {
-		if !seen[name] {
-			t.Fatalf("OCI archive missing %s", name)
-		}
+	if len(res[0].Layers) != 2 || res[0].Layers[0].Size == 0 {
+		t.Fatalf("describeImages() layers = %+v, want 2 non-empty layers", res[0].Layers)
 	}
 }
 
@@ -183,6 +481,18 @@ func imageWithPlatform(t *testing.T, platform types.Platform) v1.Image {
 func pushImageManifest(t *testing.T, img v1.Image) *remote.Descriptor {
 	t.Helper()
 
+	ref := newTestRegistry(t) + "/base:latest"
+	writeTestImage(t, ref, img)
+	desc, err := remote.Get(mustParseReference(t, ref))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+
 	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
 	t.Cleanup(server.Close)
 
@@ -190,16 +500,42 @@ func pushImageManifest(t *testing.T, img v1.Image) *remote.Descriptor {
 	if err != nil {
 		t.Fatal(err)
 	}
-	ref, err := name.NewTag(u.Host+"/base:latest", name.Insecure)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if err := remote.Write(ref, img); err != nil {
+	return u.Host
+}
+
+func writeTestImage(t *testing.T, ref string, img v1.Image) {
+	t.Helper()
+
+	if err := remote.Write(mustParseReference(t, ref), img); err != nil {
 		t.Fatal(err)
 	}
-	desc, err := remote.Get(ref)
+}
+
+func mustParseReference(t *testing.T, ref string) name.Reference {
+	t.Helper()
+
+	out, err := name.ParseReference(ref, name.Insecure)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return desc
+	return out
+}
+
+// writeTestMain writes a minimal main package to a temporary module, changes
+// the working directory to it, and returns its directory.
+func writeTestMain(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
 }
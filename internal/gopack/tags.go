@@ -0,0 +1,213 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/git"
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// TagStrategySemver derives tags from the semantic version git tag pointing at
+// HEAD. A tag of v1.4.2 produces 1.4.2, 1.4, 1 and latest. Prereleases only
+// produce the full version.
+const TagStrategySemver = "semver"
+
+var (
+	semverRegexp     = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+	invalidTagRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+)
+
+// resolveTags returns the literal tags combined with the tags produced by the
+// tag strategy and templates, which read the git repository of the workdir. If
+// none are provided, the default tag is used.
+func resolveTags(ctx context.Context, opts *runOptions) ([]string, error) {
+	tags := slices.Clone(opts.tags)
+
+	switch opts.tagStrategy {
+	case "":
+	case TagStrategySemver:
+		out, err := semverTagsFromGit(ctx, opts.workdir)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, out...)
+	default:
+		return nil, fmt.Errorf("unsupported tag strategy %q (supported: %s)", opts.tagStrategy, TagStrategySemver)
+	}
+
+	if len(opts.tagTemplates) > 0 {
+		data := &tagData{ctx: ctx, dir: opts.workdir, now: time.Now()}
+		for _, raw := range opts.tagTemplates {
+			tag, err := renderTag(raw, data)
+			if err != nil {
+				return nil, err
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return []string{oci.DefaultTag}, nil
+	}
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, err := name.NewTag("example.com/image:" + tag); err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out, nil
+}
+
+func semverTagsFromGit(ctx context.Context, dir string) ([]string, error) {
+	refs, err := git.Tags(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("tag strategy semver: %w", err)
+	}
+	for _, ref := range refs {
+		if tags, ok := semverTags(ref); ok {
+			return tags, nil
+		}
+	}
+	return nil, fmt.Errorf("tag strategy semver: no semantic version tag points at HEAD (found %s)", strings.Join(refs, ", "))
+}
+
+// semverTags returns the tags for the semantic version v, or false if v is not
+// a semantic version. Build metadata is dropped, as "+" is not valid in tags.
+func semverTags(v string) ([]string, bool) {
+	m := semverRegexp.FindStringSubmatch(v)
+	if m == nil {
+		return nil, false
+	}
+	major, minor, patch, pre := m[1], m[2], m[3], m[4]
+	if pre != "" {
+		return []string{major + "." + minor + "." + patch + "-" + pre}, true
+	}
+
+	tags := []string{major + "." + minor + "." + patch, major + "." + minor}
+	if major != "0" {
+		tags = append(tags, major)
+	}
+	return append(tags, oci.DefaultTag), true
+}
+
+func renderTag(raw string, data *tagData) (string, error) {
+	tmpl, err := template.New("tag").Option("missingkey=error").Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("parsing tag template %q: %w", raw, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("executing tag template %q: %w", raw, err)
+	}
+	return b.String(), nil
+}
+
+// tagData is the data available to tag templates. Git is only consulted, in
+// dir, for the fields a template uses.
+type tagData struct {
+	ctx context.Context
+	dir string
+	now time.Time
+}
+
+// Commit returns the full hash of the HEAD commit.
+func (d *tagData) Commit() (string, error) {
+	return git.Commit(d.ctx, d.dir)
+}
+
+// ShortCommit returns the first 7 characters of the HEAD commit hash.
+func (d *tagData) ShortCommit() (string, error) {
+	commit, err := d.Commit()
+	if err != nil {
+		return "", err
+	}
+	return commit[:min(len(commit), 7)], nil
+}
+
+// Branch returns the current branch name, with characters that are not valid
+// in tags replaced by "-".
+func (d *tagData) Branch() (string, error) {
+	branch, err := git.Branch(d.ctx, d.dir)
+	if err != nil {
+		return "", err
+	}
+	return invalidTagRegexp.ReplaceAllString(branch, "-"), nil
+}
+
+// Date returns the current UTC date formatted as YYYYMMDD.
+func (d *tagData) Date() string {
+	return d.now.UTC().Format("20060102")
+}
+
+// platformTags returns the tags of platform's image with platform tags
+// enabled: each tag suffixed with -<os>-<arch>[-<variant>].
+func platformTags(tags []string, platform types.Platform) []string {
+	suffix := "-" + strings.ReplaceAll(platform.String(), "/", "-")
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = tag + suffix
+	}
+	return out
+}
+
+// validatePlatformTags returns an error if a platform tag is invalid, which
+// happens when a tag is too long to be suffixed.
+func validatePlatformTags(tags []string, platforms []types.Platform) error {
+	for _, platform := range platforms {
+		if !platform.IsSupported() {
+			continue
+		}
+		for _, tag := range platformTags(tags, platform) {
+			if _, err := name.NewTag("example.com/image:" + tag); err != nil {
+				return fmt.Errorf("invalid platform tag %q: %w", tag, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setPlatformTags sets the tags of each image when platform tags are enabled.
+func setPlatformTags(images []ImageResult, opts *runOptions) {
+	if !opts.platformTags {
+		return
+	}
+	for i := range images {
+		platform := types.ParsePlatform(images[i].Platform)
+		if !platform.IsSupported() {
+			continue
+		}
+		tags := platformTags(opts.tags, platform)
+		images[i].Tags = make([]string, 0, len(opts.repositories)*len(tags))
+		for _, repo := range opts.repositories {
+			for _, tag := range tags {
+				images[i].Tags = append(images[i].Tags, repo+":"+tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestSemverTags(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{"v1.4.2", []string{"1.4.2", "1.4", "1", "latest"}},
+		{"2.0.0", []string{"2.0.0", "2.0", "2", "latest"}},
+		{"v0.3.1", []string{"0.3.1", "0.3", "latest"}},
+		{"v1.5.0-rc.1", []string{"1.5.0-rc.1"}},
+		{"v1.4.2+build.7", []string{"1.4.2", "1.4", "1", "latest"}},
+	}
+	for _, test := range tests {
+		got, ok := semverTags(test.version)
+		if !ok {
+			t.Fatalf("semverTags(%q) ok = false", test.version)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Fatalf("semverTags(%q) = %v, want %v", test.version, got, test.want)
+		}
+	}
+
+	for _, version := range []string{"release-1", "v1.4", "v01.2.3"} {
+		if _, ok := semverTags(version); ok {
+			t.Fatalf("semverTags(%q) ok = true, want false", version)
+		}
+	}
+}
+
+func TestResolveTags(t *testing.T) {
+	dir := initGitRepo(t)
+	runGit(t, dir, "checkout", "-q", "-b", "feature/login")
+	runGit(t, dir, "tag", "v1.4.2")
+	t.Chdir(dir)
+
+	commit := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	opts := defaultRunOptions()
+	opts.tags = []string{"edge", "latest"}
+	opts.tagStrategy = TagStrategySemver
+	opts.tagTemplates = []string{"{{.Branch}}-{{.ShortCommit}}", "sha-{{.Commit}}", "{{.Date}}"}
+
+	got, err := resolveTags(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"edge", "latest", "1.4.2", "1.4", "1",
+		"feature-login-" + commit[:7],
+		"sha-" + commit,
+		time.Now().UTC().Format("20060102"),
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("resolveTags() = %v, want %v", got, want)
+	}
+}
+
+func TestResolveTagsWorkdir(t *testing.T) {
+	dir := initGitRepo(t)
+	runGit(t, dir, "tag", "v1.4.2")
+	other := initGitRepo(t)
+	runGit(t, other, "checkout", "-q", "-b", "other")
+	runGit(t, other, "tag", "v2.0.0")
+	t.Chdir(other)
+
+	commit := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	opts := defaultRunOptions()
+	opts.workdir = dir
+	opts.tagStrategy = TagStrategySemver
+	opts.tagTemplates = []string{"{{.Branch}}-{{.Commit}}"}
+
+	got, err := resolveTags(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1.4.2", "1.4", "1", "latest", "main-" + commit}
+	if !slices.Equal(got, want) {
+		t.Fatalf("resolveTags() = %v, want %v", got, want)
+	}
+}
+
+func TestResolveTagsErrors(t *testing.T) {
+	dir := initGitRepo(t)
+	t.Chdir(dir)
+
+	tests := []struct {
+		name string
+		opts func(*runOptions)
+		want string
+	}{
+		{"untagged", func(ro *runOptions) { ro.tagStrategy = TagStrategySemver }, "no git tag points at HEAD"},
+		{"strategy", func(ro *runOptions) { ro.tagStrategy = "calver" }, `unsupported tag strategy "calver"`},
+		{"field", func(ro *runOptions) { ro.tagTemplates = []string{"{{.Version}}"} }, "executing tag template"},
+		{"invalid", func(ro *runOptions) { ro.tagTemplates = []string{"a b"} }, `invalid tag "a b"`},
+	}
+	for _, test := range tests {
+		opts := defaultRunOptions()
+		test.opts(opts)
+		_, err := resolveTags(context.Background(), opts)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("%s: resolveTags() error = %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestResolveTagsDefault(t *testing.T) {
+	got, err := resolveTags(context.Background(), defaultRunOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"latest"}) {
+		t.Fatalf("resolveTags() = %v, want [latest]", got)
+	}
+}
+
+func TestPlatformTags(t *testing.T) {
+	got := platformTags([]string{"v1.2.0", "latest"}, types.ParsePlatform("linux/arm/v7"))
+	if want := []string{"v1.2.0-linux-arm-v7", "latest-linux-arm-v7"}; !slices.Equal(got, want) {
+		t.Fatalf("platformTags() = %v, want %v", got, want)
+	}
+	if got := platformTags([]string{"v1"}, types.ParsePlatform("linux/amd64")); !slices.Equal(got, []string{"v1-linux-amd64"}) {
+		t.Fatalf("platformTags() = %v, want [v1-linux-amd64]", got)
+	}
+}
+
+func initGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// upxArchs contains the linux architectures UPX can pack executables for.
+var upxArchs = map[string]bool{
+	"386":     true,
+	"amd64":   true,
+	"arm":     true,
+	"arm64":   true,
+	"mips":    true,
+	"mipsle":  true,
+	"ppc64le": true,
+}
+
+// upxPacker packs binaries with the upx executable, if one is installed.
+type upxPacker struct {
+	path   string
+	logger types.Logger
+}
+
+// newUPXPacker returns a packer using the upx executable on the PATH. If none
+// is found, a warning is logged and binaries are left unpacked.
+func newUPXPacker(logger types.Logger) *upxPacker {
+	path, err := exec.LookPath("upx")
+	if err != nil {
+		logger.Warnf("upx was not found in PATH; binaries will not be packed\n")
+		return &upxPacker{logger: logger}
+	}
+	return &upxPacker{path: path, logger: logger}
+}
+
+// Pack compresses the binary at binPath in place.
+func (p *upxPacker) Pack(ctx context.Context, binPath string, platform types.Platform) error {
+	if p.path == "" {
+		return nil
+	}
+	if platform.OS() != "linux" || !upxArchs[platform.Arch()] {
+		p.logger.Warnf("upx does not support %s; binary will not be packed\n", platform)
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, p.path, "-q", binPath)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("upx: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
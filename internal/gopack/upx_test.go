@@ -0,0 +1,70 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+func TestUPXPacker(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf packed > \"$2\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "upx"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	packer := newUPXPacker(NopLogger())
+	for _, test := range []struct {
+		platform string
+		want     string
+	}{
+		{"linux/amd64", "packed"},
+		{"linux/s390x", "binary"},
+	} {
+		binPath := filepath.Join(t.TempDir(), "app")
+		if err := os.WriteFile(binPath, []byte("binary"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := packer.Pack(context.Background(), binPath, types.ParsePlatform(test.platform)); err != nil {
+			t.Fatalf("%s: Pack() error = %v", test.platform, err)
+		}
+		got, err := os.ReadFile(binPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Fatalf("%s: binary = %q, want %q", test.platform, got, test.want)
+		}
+	}
+}
+
+func TestUPXPackerWithoutUPX(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	binPath := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(binPath, []byte("binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := newUPXPacker(NopLogger()).Pack(context.Background(), binPath, types.ParsePlatform("linux/amd64"))
+	if err != nil {
+		t.Fatalf("Pack() error = %v, want binary left unpacked", err)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/google/go-containerregistry/pkg/authn"
+)
+
+var acrRegistryPattern = regexp.MustCompile(`^[a-zA-Z0-9]+\.azurecr\.(?:io|cn|us)$`)
+
+// acrUsername is the username ACR expects when authenticating with a refresh
+// token obtained from an Entra ID token exchange.
+const acrUsername = "00000000-0000-0000-0000-000000000000"
+
+// acrRefreshTokenTTL is how long a refresh token is reused. ACR refresh tokens
+// are valid for three hours.
+const acrRefreshTokenTTL = 2 * time.Hour
+
+// acrKeychain resolves credentials for Azure Container Registry by exchanging
+// an Entra ID access token, obtained with the Azure SDK's default credential
+// chain, for an ACR refresh token. Without Azure credentials, registries are
+// accessed anonymously, so that public images can still be pulled.
+type acrKeychain struct {
+	// newCredential returns the credential used to obtain access tokens. It
+	// defaults to azidentity.NewDefaultAzureCredential.
+	newCredential func() (azcore.TokenCredential, error)
+	// exchangeURL returns the token exchange endpoint of the registry.
+	exchangeURL func(registry string) string
+	// transport sends token exchange requests, so that they use the same
+	// CAs and timeouts as other registry requests.
+	transport http.RoundTripper
+
+	cache *acrTokenCache
+}
+
+// acrTokenCache holds refresh tokens by registry. An empty refresh token
+// records that no Azure credentials were found.
+type acrTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]acrToken
+}
+
+type acrToken struct {
+	refreshToken string
+	expires      time.Time
+}
+
+// acrTokens is shared by every acrKeychain, so that tokens are reused across
+// operations.
+var acrTokens = &acrTokenCache{}
+
+// errNoAzureCredentials is returned by exchange when no Azure credentials are
+// available.
+var errNoAzureCredentials = errors.New("no Azure credentials")
+
+// newACRKeychain returns an acrKeychain that sends token exchange requests
+// with t, over plain HTTP for insecure registries.
+func newACRKeychain(t http.RoundTripper, insecure func(registry string) bool) *acrKeychain {
+	return &acrKeychain{
+		newCredential: func() (azcore.TokenCredential, error) {
+			return azidentity.NewDefaultAzureCredential(nil)
+		},
+		exchangeURL: func(registry string) string {
+			scheme := "https"
+			if insecure(registry) {
+				scheme = "http"
+			}
+			return scheme + "://" + registry + "/oauth2/exchange"
+		},
+		transport: t,
+		cache:     acrTokens,
+	}
+}
+
+func (k *acrKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	return k.ResolveContext(context.Background(), r)
+}
+
+func (k *acrKeychain) ResolveContext(ctx context.Context, r authn.Resource) (authn.Authenticator, error) {
+	registry := r.RegistryStr()
+	if !acrRegistryPattern.MatchString(registry) {
+		return authn.Anonymous, nil
+	}
+
+	k.cache.mu.Lock()
+	defer k.cache.mu.Unlock()
+	if token, ok := k.cache.tokens[registry]; ok && time.Now().Before(token.expires) {
+		return acrAuthenticator(token.refreshToken), nil
+	}
+
+	refreshToken, err := k.exchange(ctx, registry)
+	if err != nil && !errors.Is(err, errNoAzureCredentials) {
+		return nil, fmt.Errorf("acr: %s: %w", registry, err)
+	}
+	if k.cache.tokens == nil {
+		k.cache.tokens = make(map[string]acrToken)
+	}
+	k.cache.tokens[registry] = acrToken{
+		refreshToken: refreshToken,
+		expires:      time.Now().Add(acrRefreshTokenTTL),
+	}
+	return acrAuthenticator(refreshToken), nil
+}
+
+func acrAuthenticator(refreshToken string) authn.Authenticator {
+	if refreshToken == "" {
+		return authn.Anonymous
+	}
+	return authn.FromConfig(authn.AuthConfig{
+		Username: acrUsername,
+		Password: refreshToken,
+	})
+}
+
+// exchange trades an Entra ID access token for an ACR refresh token. It
+// returns errNoAzureCredentials if no access token can be obtained.
+func (k *acrKeychain) exchange(ctx context.Context, registry string) (string, error) {
+	cred, err := k.newCredential()
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errNoAzureCredentials, err)
+	}
+	accessToken, err := cred.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{"https://management.azure.com/.default"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errNoAzureCredentials, err)
+	}
+
+	form := url.Values{
+		"grant_type":   {"access_token"},
+		"service":      {registry},
+		"access_token": {accessToken.Token},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.exchangeURL(registry), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := &http.Client{Transport: k.transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("exchanging access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("exchanging access token: unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("exchanging access token: %w", err)
+	}
+	if body.RefreshToken == "" {
+		return "", errors.New("exchanging access token: no refresh token returned")
+	}
+	return body.RefreshToken, nil
+}
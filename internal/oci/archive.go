@@ -0,0 +1,195 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+)
+
+// WriteArchive writes index as an OCI image layout tarball at path.
+func WriteArchive(path string, index v1.ImageIndex) error {
+	dir, err := os.MkdirTemp("", "gopack-oci-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := layout.Write(dir, index); err != nil {
+		return fmt.Errorf("writing OCI layout: %w", err)
+	}
+	return tarDirectory(path, dir)
+}
+
+// Archive is an OCI image layout tarball opened for reading.
+type Archive struct {
+	dir string
+	// Index is the layout's top-level index. Its digest and manifests are
+	// exactly those that were written.
+	Index v1.ImageIndex
+}
+
+// OpenArchive extracts the OCI image layout tarball at path to a temporary
+// directory and reads its index. The returned Archive must be closed.
+func OpenArchive(path string) (*Archive, error) {
+	dir, err := os.MkdirTemp("", "gopack-oci-")
+	if err != nil {
+		return nil, err
+	}
+	if err := untar(path, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("reading OCI archive %s: %w", path, err)
+	}
+	index, err := layout.ImageIndexFromPath(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("reading OCI archive %s: %w", path, err)
+	}
+	return &Archive{dir: dir, Index: index}, nil
+}
+
+// Close removes the extracted layout.
+func (a *Archive) Close() error {
+	return os.RemoveAll(a.dir)
+}
+
+func tarDirectory(path string, dir string) (err error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	tw := tar.NewWriter(out)
+	defer func() {
+		if closeErr := tw.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		_, copyErr := io.Copy(tw, in)
+		closeErr := in.Close()
+		if copyErr != nil {
+			return copyErr
+		}
+		return closeErr
+	})
+	return err
+}
+
+// untar extracts the regular files and directories of the tarball at path
+// into dir, rejecting entries that would escape it.
+func untar(path string, dir string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %q", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry %q", strings.TrimSuffix(hdr.Name, "/"))
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	_, err = io.Copy(out, r)
+	return err
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestWriteArchive(t *testing.T) {
+	index, err := random.Index(1024, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "image.tar")
+	if err := WriteArchive(path, index); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	seen := map[string]bool{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen[hdr.Name] = true
+	}
+
+	for _, name := range []string{"oci-layout", "index.json"} {
+		if !seen[name] {
+			t.Fatalf("OCI archive missing %s", name)
+		}
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	index, err := random.Index(1024, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "image.tar")
+	if err := WriteArchive(path, index); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	want, err := index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := archive.Index.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("archive index digest = %s, want %s", got, want)
+	}
+	manifest, err := archive.Index.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Manifests) != 2 {
+		t.Fatalf("archive index has %d manifests, want 2", len(manifest.Manifests))
+	}
+	img, err := archive.Index.Image(manifest.Manifests[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := img.Layers(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenArchiveRejectsEscapingPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tw := tar.NewWriter(f)
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = OpenArchive(path)
+	if err == nil || !strings.Contains(err.Error(), `invalid path "../escape"`) {
+		t.Fatalf("OpenArchive() error = %v, want invalid path error", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/docker/cli/cli/config"
+	dockertypes "github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/authn/github"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// sharedECRKeychain caches ECR authorization tokens across operations.
+var sharedECRKeychain = &ecrKeychain{}
+
+// AuthError is returned when a registry rejects the credentials used for an
+// operation, or when no credentials were found for a registry that requires
+// them.
+type AuthError struct {
+	Registry string
+	// Op is the denied operation, either "pull" or "push".
+	Op  string
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("registry %s denied %s: %v", e.Registry, e.Op, e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// wrapAuthError returns err as an *AuthError if it was caused by the registry
+// rejecting the request's credentials.
+func wrapAuthError(err error, registry, op string) error {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return err
+	}
+	if terr.StatusCode != http.StatusUnauthorized && terr.StatusCode != http.StatusForbidden {
+		return err
+	}
+	return &AuthError{Registry: registry, Op: op, Err: err}
+}
+
+// keychain returns the keychain used to resolve credentials, preferring any
+// explicitly configured credentials over the default keychains. The default
+// keychains prefer credentials from the Docker config file, falling back to
+// the cloud provider keychains for their registries. Requests made to obtain
+// credentials are sent with t.
+func (o *registryOptions) keychain(t http.RoundTripper) authn.Keychain {
+	defaults := authn.NewMultiKeychain(
+		authn.DefaultKeychain,
+		google.Keychain,
+		github.Keychain,
+		sharedECRKeychain,
+		newACRKeychain(t, o.isInsecure),
+	)
+	if len(o.credentials) == 0 {
+		return defaults
+	}
+	return authn.NewMultiKeychain(credentialKeychain(o.credentials), defaults)
+}
+
+// credentialKeychain resolves explicitly configured credentials by registry.
+type credentialKeychain map[string]types.Credential
+
+func (k credentialKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	for registry, cred := range k {
+		reg, err := name.NewRegistry(registry)
+		if err != nil || reg.RegistryStr() != r.RegistryStr() {
+			continue
+		}
+		return credentialAuthenticator(cred, r.RegistryStr())
+	}
+	return authn.Anonymous, nil
+}
+
+func credentialAuthenticator(cred types.Credential, registry string) (authn.Authenticator, error) {
+	switch {
+	case cred.Helper != "":
+		return helperAuthenticator(cred.Helper, registry)
+	case cred.TokenFile != "":
+		return tokenFile(cred.TokenFile), nil
+	default:
+		return authn.FromConfig(authn.AuthConfig{
+			Username: cred.Username,
+			Password: cred.Password,
+		}), nil
+	}
+}
+
+// helperAuthenticator returns the credentials stored for registry by the
+// docker-credential-<helper> program. Unlike authn.NewKeychainFromHelper,
+// helper failures are returned rather than treated as anonymous access.
+func helperAuthenticator(helper, registry string) (authn.Authenticator, error) {
+	creds, err := client.Get(client.NewShellProgramFunc("docker-credential-"+helper), registry)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %q for %s: %w", helper, registry, err)
+	}
+	if creds.Username == "<token>" {
+		return authn.FromConfig(authn.AuthConfig{IdentityToken: creds.Secret}), nil
+	}
+	return authn.FromConfig(authn.AuthConfig{
+		Username: creds.Username,
+		Password: creds.Secret,
+	}), nil
+}
+
+// tokenFile is an authenticator that reads a bearer token from a file on
+// every use, so that rotated tokens are picked up.
+type tokenFile string
+
+func (f tokenFile) Authorization() (*authn.AuthConfig, error) {
+	raw, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, fmt.Errorf("reading registry token: %w", err)
+	}
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return nil, fmt.Errorf("reading registry token: %s is empty", f)
+	}
+	return &authn.AuthConfig{RegistryToken: token}, nil
+}
+
+// Login verifies the username and password against the registry and stores
+// them in the Docker config file.
+func Login(ctx context.Context, registry, username, password string, options ...RegistryOption) error {
+	opts := defaultRegistryOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	reg, err := opts.resolveRegistry(registry)
+	if err != nil {
+		return err
+	}
+	auth := authn.FromConfig(authn.AuthConfig{Username: username, Password: password})
+	if err := checkAuth(ctx, reg, auth, opts); err != nil {
+		return err
+	}
+
+	cf, err := config.Load(os.Getenv("DOCKER_CONFIG"))
+	if err != nil {
+		return fmt.Errorf("loading docker config: %w", err)
+	}
+	key := configKey(reg)
+	err = cf.GetCredentialsStore(key).Store(dockertypes.AuthConfig{
+		ServerAddress: key,
+		Username:      username,
+		Password:      password,
+	})
+	if err != nil {
+		return fmt.Errorf("storing credentials: %w", err)
+	}
+	return nil
+}
+
+// Logout removes any stored credentials for the registry from the Docker
+// config file.
+func Logout(registry string) error {
+	reg, err := name.NewRegistry(registry)
+	if err != nil {
+		return err
+	}
+	cf, err := config.Load(os.Getenv("DOCKER_CONFIG"))
+	if err != nil {
+		return fmt.Errorf("loading docker config: %w", err)
+	}
+	key := configKey(reg)
+	if err := cf.GetCredentialsStore(key).Erase(key); err != nil {
+		return fmt.Errorf("removing credentials: %w", err)
+	}
+	return nil
+}
+
+// configKey returns the key that credentials for reg are stored under in the
+// Docker config file.
+func configKey(reg name.Registry) string {
+	if reg.RegistryStr() == name.DefaultRegistry {
+		return authn.DefaultAuthKey
+	}
+	return reg.RegistryStr()
+}
+
+// checkAuth verifies that the registry accepts auth.
+func checkAuth(ctx context.Context, reg name.Registry, auth authn.Authenticator, opts *registryOptions) error {
+	t, err := opts.transport()
+	if err != nil {
+		return err
+	}
+	rt, err := transport.NewWithContext(ctx, reg, auth, t, []string{reg.Scope(transport.PullScope)})
+	if err != nil {
+		return wrapAuthError(err, reg.RegistryStr(), "login")
+	}
+
+	u := fmt.Sprintf("%s://%s/v2/", reg.Scheme(), reg.RegistryStr())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		return wrapAuthError(err, reg.RegistryStr(), "login")
+	}
+	return nil
+}
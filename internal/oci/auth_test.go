@@ -0,0 +1,357 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestLoginAndLogout(t *testing.T) {
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	host := newBasicAuthRegistry(t, "user", "secret")
+
+	err := Login(context.Background(), host, "user", "wrong", WithRetries(0))
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Login() error = %v, want *AuthError", err)
+	}
+
+	if err := Login(context.Background(), host, "user", "secret", WithRetries(0)); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), host) {
+		t.Fatalf("docker config missing %s: %s", host, raw)
+	}
+
+	repo, err := name.NewRepository(host + "/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(context.Background(), repo, img, WithRegistryOptions(WithRetries(0))); err != nil {
+		t.Fatalf("Push() with stored credentials error = %v", err)
+	}
+
+	if err := Logout(host); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	err = Push(context.Background(), repo, img, WithRegistryOptions(WithRetries(0)))
+	if !errors.As(err, &authErr) || authErr.Op != "push" {
+		t.Fatalf("Push() after logout error = %v, want push *AuthError", err)
+	}
+}
+
+func TestCredentialKeychain(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("abc123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kc := credentialKeychain{
+		"docker.io":       {Username: "user", Password: "pass"},
+		"ghcr.io":         {TokenFile: tokenPath},
+		"registry.local":  {Username: "other", Password: "other"},
+		"invalid/name!!!": {Username: "bad"},
+	}
+
+	tests := []struct {
+		registry string
+		want     authn.AuthConfig
+	}{
+		{"index.docker.io", authn.AuthConfig{Username: "user", Password: "pass"}},
+		{"ghcr.io", authn.AuthConfig{RegistryToken: "abc123"}},
+		{"quay.io", authn.AuthConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.registry, func(t *testing.T) {
+			reg, err := name.NewRegistry(tt.registry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth, err := kc.Resolve(reg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := auth.Authorization()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != tt.want {
+				t.Fatalf("Authorization() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialKeychainHelperError(t *testing.T) {
+	kc := credentialKeychain{"ghcr.io": types.Credential{Helper: "gopack-test-missing"}}
+	reg, err := name.NewRegistry("ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kc.Resolve(reg); err == nil || !strings.Contains(err.Error(), "gopack-test-missing") {
+		t.Fatalf("Resolve() error = %v, want credential helper error", err)
+	}
+}
+
+func TestECRKeychain(t *testing.T) {
+	var calls int
+	stub := newECRStub(t, func(target string, body map[string]any) (int, any) {
+		if target != "GetAuthorizationToken" {
+			t.Fatalf("unexpected ECR call %s", target)
+		}
+		calls++
+		return http.StatusOK, map[string]any{
+			"authorizationData": []map[string]any{{
+				"authorizationToken": base64.StdEncoding.EncodeToString([]byte("AWS:ecr-password")),
+				"expiresAt":          float64(time.Now().Add(12 * time.Hour).Unix()),
+			}},
+		}
+	})
+	setECREnv(t, stub)
+
+	kc := &ecrKeychain{}
+	reg, err := name.NewRegistry("123456789012.dkr.ecr.us-east-1.amazonaws.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		auth, err := kc.Resolve(reg)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		cfg, err := auth.Authorization()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Username != "AWS" || cfg.Password != "ecr-password" {
+			t.Fatalf("Authorization() = %+v, want ECR token", cfg)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("GetAuthorizationToken called %d times, want 1 (cached)", calls)
+	}
+
+	other, err := name.NewRegistry("ghcr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth, err := kc.Resolve(other); err != nil || auth != authn.Anonymous {
+		t.Fatalf("Resolve(ghcr.io) = %v, %v, want anonymous", auth, err)
+	}
+}
+
+func TestEnsureECRRepository(t *testing.T) {
+	var created string
+	stub := newECRStub(t, func(target string, body map[string]any) (int, any) {
+		switch target {
+		case "DescribeRepositories":
+			return http.StatusBadRequest, map[string]any{
+				"__type":  "RepositoryNotFoundException",
+				"message": "not found",
+			}
+		case "CreateRepository":
+			created, _ = body["repositoryName"].(string)
+			return http.StatusOK, map[string]any{"repository": map[string]any{}}
+		}
+		t.Fatalf("unexpected ECR call %s", target)
+		return 0, nil
+	})
+	setECREnv(t, stub)
+
+	repo, err := name.NewRepository("123456789012.dkr.ecr.us-east-1.amazonaws.com/team/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := ensureECRRepository(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("ensureECRRepository() error = %v", err)
+	}
+	if !ok || created != "team/app" {
+		t.Fatalf("ensureECRRepository() = %v, created %q, want team/app", ok, created)
+	}
+
+	other, err := name.NewRepository("ghcr.io/team/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ensureECRRepository(context.Background(), other); ok || err != nil {
+		t.Fatalf("ensureECRRepository(ghcr.io) = %v, %v, want no-op", ok, err)
+	}
+}
+
+func TestACRKeychain(t *testing.T) {
+	exchange := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.Form.Get("access_token") != "aad-token" || r.Form.Get("service") != "myregistry.azurecr.io" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"refresh_token": "acr-refresh"})
+	}))
+	t.Cleanup(exchange.Close)
+
+	var exchanges atomic.Int32
+	kc := &acrKeychain{
+		newCredential: func() (azcore.TokenCredential, error) {
+			return staticTokenCredential("aad-token"), nil
+		},
+		exchangeURL: func(string) string { return exchange.URL },
+		transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			exchanges.Add(1)
+			return http.DefaultTransport.RoundTrip(r)
+		}),
+		cache: &acrTokenCache{},
+	}
+	reg, err := name.NewRegistry("myregistry.azurecr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := kc.Resolve(reg)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Username != acrUsername || cfg.Password != "acr-refresh" {
+		t.Fatalf("Authorization() = %+v, want ACR refresh token", cfg)
+	}
+	if exchanges.Load() != 1 {
+		t.Fatalf("exchange requests through the transport = %d, want 1", exchanges.Load())
+	}
+}
+
+func TestACRKeychainWithoutCredentials(t *testing.T) {
+	kc := &acrKeychain{
+		newCredential: func() (azcore.TokenCredential, error) {
+			return nil, errors.New("no credential sources")
+		},
+		exchangeURL: func(string) string {
+			t.Fatal("token exchanged without credentials")
+			return ""
+		},
+		cache: &acrTokenCache{},
+	}
+	reg, err := name.NewRegistry("public.azurecr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := kc.Resolve(reg)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if auth != authn.Anonymous {
+		t.Fatalf("Resolve() = %v, want anonymous", auth)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type staticTokenCredential string
+
+func (c staticTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: string(c), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// newECRStub starts a server implementing the ECR JSON API by calling fn with
+// the operation name and decoded request body.
+func newECRStub(t *testing.T, fn func(target string, body map[string]any) (int, any)) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding ECR request: %v", err)
+		}
+		_, target, _ := strings.Cut(r.Header.Get("X-Amz-Target"), ".")
+		status, resp := fn(target, body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func setECREnv(t *testing.T, endpoint string) {
+	t.Helper()
+
+	t.Setenv("AWS_ENDPOINT_URL_ECR", endpoint)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+// newBasicAuthRegistry starts a registry that requires HTTP basic auth.
+func newBasicAuthRegistry(t *testing.T, username, password string) string {
+	t.Helper()
+
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != username || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
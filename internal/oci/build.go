@@ -15,22 +15,24 @@
 package oci
 
 import (
-	"archive/tar"
-	"bytes"
 	"context"
 	"fmt"
-	"io"
-	"os"
 	"path"
-	"strings"
-	"time"
+	"path/filepath"
 
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
+// zstdDefaultLevel is the zstd compression level used when no level is set.
+const zstdDefaultLevel = 3
+
+// BuildImage returns base with a layer containing the binary at goBinPath,
+// which becomes the entrypoint. The layer is written to a file in the layer
+// directory (by default, the binary's directory), which must not be removed
+// until the image has been written.
 func BuildImage(ctx context.Context, goBinPath string, base v1.Image, options ...BuildOption) (v1.Image, error) {
 	opts := defaultBuildOptions()
 	for _, o := range options {
@@ -38,21 +40,13 @@ func BuildImage(ctx context.Context, goBinPath string, base v1.Image, options ..
 	}
 
 	entrypoint := "/app/" + path.Base(goBinPath)
-	raw, err := tarGoBin(goBinPath, entrypoint)
-	if err != nil {
-		return nil, fmt.Errorf("tar go binary: %w", err)
-	}
-
-	layerOpts := []tarball.LayerOption{
-		tarball.WithCompressedCaching,
-		tarball.WithCompressionLevel(opts.gzipCompressionLevel),
+	layerDir := opts.layerDir
+	if layerDir == "" {
+		layerDir = filepath.Dir(goBinPath)
 	}
-
-	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
-		return io.NopCloser(bytes.NewReader(raw)), nil
-	}, layerOpts...)
+	layer, err := writeLayer(layerDir, goBinPath, entrypoint, opts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("writing layer: %w", err)
 	}
 
 	out, err := mutate.Append(base, mutate.Addendum{
@@ -88,45 +82,9 @@ func BuildImage(ctx context.Context, goBinPath string, base v1.Image, options ..
 		return nil, err
 	}
 
-	return mutate.MediaType(out, types.DockerManifestSchema2), nil
-}
-
-func tarGoBin(goBinPath, entrypoint string) ([]byte, error) {
-	file, err := os.Open(goBinPath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	stat, err := file.Stat()
-	if err != nil {
-		return nil, err
-	}
-
-	var buf bytes.Buffer
-	tw := tar.NewWriter(&buf)
-
-	err = tw.WriteHeader(&tar.Header{
-		Name:     strings.TrimPrefix(entrypoint, "/"),
-		Mode:     0o555,
-		Size:     stat.Size(),
-		Typeflag: tar.TypeReg,
-		ModTime:  time.Time{},
-		Uid:      0,
-		Gid:      0,
-		Uname:    "",
-		Gname:    "",
-	})
-	if err != nil {
-		return nil, err
+	if opts.compression == compression.GZip {
+		return mutate.MediaType(out, types.DockerManifestSchema2), nil
 	}
-
-	if _, err = io.Copy(tw, file); err != nil {
-		return nil, err
-	}
-	if err = tw.Close(); err != nil {
-		return nil, err
-	}
-
-	return buf.Bytes(), nil
+	out = mutate.MediaType(out, types.OCIManifestSchema1)
+	return mutate.ConfigMediaType(out, types.OCIConfigJSON), nil
 }
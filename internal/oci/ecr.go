@@ -0,0 +1,160 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrtypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+var ecrRegistryPattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// parseECRRegistry returns the account ID and region of an Amazon ECR
+// registry host.
+func parseECRRegistry(registry string) (string, string, bool) {
+	m := ecrRegistryPattern.FindStringSubmatch(registry)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// ecrKeychain resolves credentials for Amazon ECR registries with tokens
+// obtained using the AWS SDK's default credential chain. The ECR API endpoint
+// can be overridden with the AWS_ENDPOINT_URL_ECR environment variable.
+type ecrKeychain struct {
+	mu     sync.Mutex
+	tokens map[string]ecrToken
+}
+
+type ecrToken struct {
+	auth    authn.AuthConfig
+	expires time.Time
+}
+
+// ecrTokenLeeway is how long before expiry a cached token is refreshed.
+const ecrTokenLeeway = 5 * time.Minute
+
+func (k *ecrKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	return k.ResolveContext(context.Background(), r)
+}
+
+func (k *ecrKeychain) ResolveContext(ctx context.Context, r authn.Resource) (authn.Authenticator, error) {
+	registry := r.RegistryStr()
+	account, region, ok := parseECRRegistry(registry)
+	if !ok {
+		return authn.Anonymous, nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if token, ok := k.tokens[registry]; ok && time.Now().Add(ecrTokenLeeway).Before(token.expires) {
+		return authn.FromConfig(token.auth), nil
+	}
+
+	token, err := getECRToken(ctx, account, region)
+	if err != nil {
+		return nil, fmt.Errorf("ecr: %s: %w", registry, err)
+	}
+	if k.tokens == nil {
+		k.tokens = make(map[string]ecrToken)
+	}
+	k.tokens[registry] = token
+	return authn.FromConfig(token.auth), nil
+}
+
+func newECRClient(ctx context.Context, region string) (*ecr.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return ecr.NewFromConfig(cfg), nil
+}
+
+func getECRToken(ctx context.Context, account, region string) (ecrToken, error) {
+	client, err := newECRClient(ctx, region)
+	if err != nil {
+		return ecrToken{}, err
+	}
+	out, err := client.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return ecrToken{}, fmt.Errorf("getting authorization token: %w", err)
+	}
+	if len(out.AuthorizationData) == 0 {
+		return ecrToken{}, fmt.Errorf("getting authorization token: no token returned for account %s", account)
+	}
+
+	data := out.AuthorizationData[0]
+	raw, err := base64.StdEncoding.DecodeString(aws.ToString(data.AuthorizationToken))
+	if err != nil {
+		return ecrToken{}, fmt.Errorf("decoding authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return ecrToken{}, errors.New("decoding authorization token: invalid format")
+	}
+	return ecrToken{
+		auth:    authn.AuthConfig{Username: username, Password: password},
+		expires: aws.ToTime(data.ExpiresAt),
+	}, nil
+}
+
+// ensureECRRepository creates repo if it is in an Amazon ECR registry and does
+// not already exist. Repositories in other registries are ignored.
+func ensureECRRepository(ctx context.Context, repo name.Repository) (bool, error) {
+	account, region, ok := parseECRRegistry(repo.RegistryStr())
+	if !ok {
+		return false, nil
+	}
+	client, err := newECRClient(ctx, region)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = client.DescribeRepositories(ctx, &ecr.DescribeRepositoriesInput{
+		RegistryId:      aws.String(account),
+		RepositoryNames: []string{repo.RepositoryStr()},
+	})
+	if err == nil {
+		return false, nil
+	}
+	var notFound *ecrtypes.RepositoryNotFoundException
+	if !errors.As(err, &notFound) {
+		return false, fmt.Errorf("ecr: describing repository %s: %w", repo.RepositoryStr(), err)
+	}
+
+	_, err = client.CreateRepository(ctx, &ecr.CreateRepositoryInput{
+		RegistryId:     aws.String(account),
+		RepositoryName: aws.String(repo.RepositoryStr()),
+	})
+	var exists *ecrtypes.RepositoryAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return false, fmt.Errorf("ecr: creating repository %s: %w", repo.RepositoryStr(), err)
+	}
+	return err == nil, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// ImmutableTagError is returned when pushing would move an immutable tag to a
+// different digest.
+type ImmutableTagError struct {
+	Tag string
+	// Existing is the digest the tag currently points at.
+	Existing v1.Hash
+	// Digest is the digest that was being pushed.
+	Digest v1.Hash
+}
+
+func (e *ImmutableTagError) Error() string {
+	return fmt.Sprintf("immutable tag %s already points at %s, refusing to move it to %s",
+		e.Tag, e.Existing, e.Digest)
+}
+
+// ValidateTagPatterns returns an error if any immutable tag pattern is
+// malformed.
+func ValidateTagPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid immutable tag pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CheckImmutableTags returns an *ImmutableTagError if an immutable tag already
+// points at a digest other than img's in any of repos. Nothing is written, so
+// a push can be refused before any repository has changed.
+func CheckImmutableTags(ctx context.Context, repos []name.Repository, img remote.Taggable, options ...PushOption) error {
+	opts := defaultPushOptions()
+	for _, o := range options {
+		o(opts)
+	}
+	if len(opts.immutableTags) == 0 {
+		return nil
+	}
+
+	digest, err := digestOf(img)
+	if err != nil {
+		return err
+	}
+	t, err := opts.registry.transport()
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		repo, err := opts.registry.resolveRepository(repo)
+		if err != nil {
+			return err
+		}
+		if err := checkImmutable(ctx, repo, digest, opts, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkExisting reports whether digest already exists in repo when
+// opts.skipIfExists is set, and fails if an immutable tag already points at a
+// different digest.
+func checkExisting(ctx context.Context, repo name.Repository, digest v1.Hash, opts *pushOptions, t http.RoundTripper) (bool, error) {
+	var exists bool
+	if opts.skipIfExists {
+		_, err := remote.Head(repo.Digest(digest.String()), opts.registry.remoteOptions(ctx, t)...)
+		if err != nil && !isNotFound(err) {
+			return false, fmt.Errorf("checking %s: %w", repo, wrapAuthError(err, repo.RegistryStr(), "pull"))
+		}
+		exists = err == nil
+	}
+	return exists, checkImmutable(ctx, repo, digest, opts, t)
+}
+
+// checkImmutable fails if an immutable tag in repo already points at a digest
+// other than digest.
+func checkImmutable(ctx context.Context, repo name.Repository, digest v1.Hash, opts *pushOptions, t http.RoundTripper) error {
+	remoteOpts := opts.registry.remoteOptions(ctx, t)
+	for _, raw := range opts.tags {
+		if !opts.isImmutable(raw) {
+			continue
+		}
+		tag := repo.Tag(raw)
+		desc, err := remote.Head(tag, remoteOpts...)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("checking %s: %w", tag, wrapAuthError(err, repo.RegistryStr(), "pull"))
+		}
+		if desc.Digest != digest {
+			return &ImmutableTagError{Tag: tag.String(), Existing: desc.Digest, Digest: digest}
+		}
+	}
+	return nil
+}
+
+func (o *pushOptions) isImmutable(tag string) bool {
+	for _, pattern := range o.immutableTags {
+		if ok, _ := path.Match(pattern, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
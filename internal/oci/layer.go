@@ -0,0 +1,206 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+var _ v1.Layer = (*fileLayer)(nil)
+
+// fileLayer is a layer whose compressed contents are stored in a file, with
+// its digest, diff ID and size computed when the file was written. Reading
+// the layer streams from the file, so memory use does not depend on the
+// layer's size.
+type fileLayer struct {
+	path        string
+	compression compression.Compression
+	mediaType   types.MediaType
+	digest      v1.Hash
+	diffID      v1.Hash
+	size        int64
+}
+
+// writeLayer writes a layer containing the binary at goBinPath as entrypoint
+// to a new file in dir, compressed as configured. The tarball is streamed
+// from the binary through the compressor to the file, hashing both the
+// uncompressed and compressed bytes on the way.
+func writeLayer(dir, goBinPath, entrypoint string, opts *buildOptions) (_ *fileLayer, err error) {
+	layer := &fileLayer{compression: opts.compression}
+	switch opts.compression {
+	case compression.GZip:
+		layer.mediaType = types.DockerLayer
+	case compression.ZStd:
+		layer.mediaType = types.OCILayerZStd
+	case compression.None:
+		layer.mediaType = types.OCIUncompressedLayer
+	default:
+		return nil, fmt.Errorf("unsupported layer compression %q", opts.compression)
+	}
+
+	bin, err := os.Open(goBinPath)
+	if err != nil {
+		return nil, err
+	}
+	defer bin.Close()
+	stat, err := bin.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.CreateTemp(dir, "layer-*")
+	if err != nil {
+		return nil, err
+	}
+	layer.path = f.Name()
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(layer.path)
+		}
+	}()
+
+	compressed := &hashWriter{w: f, h: sha256.New()}
+	cw, err := newCompressor(compressed, opts)
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := &hashWriter{w: cw, h: sha256.New()}
+	tw := tar.NewWriter(uncompressed)
+	err = tw.WriteHeader(&tar.Header{
+		Name:     strings.TrimPrefix(entrypoint, "/"),
+		Mode:     0o555,
+		Size:     stat.Size(),
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Time{},
+		Uid:      0,
+		Gid:      0,
+		Uname:    "",
+		Gname:    "",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tw, bin); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := cw.Close(); err != nil {
+		return nil, err
+	}
+
+	layer.digest = compressed.hash()
+	layer.diffID = uncompressed.hash()
+	layer.size = compressed.n
+	return layer, nil
+}
+
+// newCompressor returns a writer compressing to w as configured. The output
+// matches that of the tarball package, so layers keep their digests.
+func newCompressor(w io.Writer, opts *buildOptions) (io.WriteCloser, error) {
+	switch opts.compression {
+	case compression.GZip:
+		return gzip.NewWriterLevel(w, opts.gzipCompressionLevel)
+	case compression.ZStd:
+		level := opts.gzipCompressionLevel
+		if level < 0 {
+			level = zstdDefaultLevel
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+func (l *fileLayer) Digest() (v1.Hash, error)            { return l.digest, nil }
+func (l *fileLayer) DiffID() (v1.Hash, error)            { return l.diffID, nil }
+func (l *fileLayer) Size() (int64, error)                { return l.size, nil }
+func (l *fileLayer) MediaType() (types.MediaType, error) { return l.mediaType, nil }
+
+func (l *fileLayer) Compressed() (io.ReadCloser, error) {
+	return os.Open(l.path)
+}
+
+func (l *fileLayer) Uncompressed() (io.ReadCloser, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, err
+	}
+	switch l.compression {
+	case compression.GZip:
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{Reader: zr, close: func() error { zr.Close(); return f.Close() }}, nil
+	case compression.ZStd:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return readCloser{Reader: zr, close: func() error { zr.Close(); return f.Close() }}, nil
+	default:
+		return f, nil
+	}
+}
+
+// hashWriter writes to w, hashing and counting the bytes written.
+type hashWriter struct {
+	w io.Writer
+	h hash.Hash
+	n int64
+}
+
+func (w *hashWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.h.Write(p[:n])
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *hashWriter) hash() v1.Hash {
+	return v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(w.h.Sum(nil))}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r readCloser) Close() error { return r.close() }
@@ -0,0 +1,97 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+func TestWriteLayer(t *testing.T) {
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "app")
+	content := bytes.Repeat([]byte("gopack"), 100_000)
+	if err := os.WriteFile(binPath, content, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []compression.Compression{compression.GZip, compression.ZStd, compression.None} {
+		t.Run(string(c), func(t *testing.T) {
+			opts := defaultBuildOptions()
+			opts.compression = c
+			layer, err := writeLayer(dir, binPath, "/app/app", opts)
+			if err != nil {
+				t.Fatalf("writeLayer() error = %v", err)
+			}
+
+			// The layer must match one built by the tarball package from
+			// the same uncompressed contents, as layers were built before.
+			level := opts.gzipCompressionLevel
+			if c == compression.ZStd {
+				level = zstdDefaultLevel
+			}
+			want, err := tarball.LayerFromOpener(layer.Uncompressed,
+				tarball.WithCompression(c), tarball.WithCompressionLevel(level))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertHashEqual(t, "DiffID", layer.DiffID, want.DiffID)
+			if c != compression.None {
+				assertHashEqual(t, "Digest", layer.Digest, want.Digest)
+			}
+
+			rc, err := layer.Uncompressed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rc.Close()
+			tr := tar.NewReader(rc)
+			hdr, err := tr.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Name != "app/app" || hdr.Mode != 0o555 || !bytes.Equal(got, content) {
+				t.Fatalf("layer contains %s (mode %o, %d bytes), want app/app with the binary", hdr.Name, hdr.Mode, len(got))
+			}
+		})
+	}
+}
+
+func assertHashEqual(t *testing.T, name string, got, want func() (v1.Hash, error)) {
+	t.Helper()
+	gotHash, err := got()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantHash, err := want()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotHash != wantHash {
+		t.Fatalf("%s() = %s, want %s", name, gotHash, wantHash)
+	}
+}
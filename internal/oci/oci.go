@@ -16,8 +16,11 @@ package oci
 
 import (
 	"compress/gzip"
+	"time"
 
 	"github.com/ryanfowler/gopack/internal/types"
+
+	"github.com/google/go-containerregistry/pkg/compression"
 )
 
 var DefaultTag = "latest"
@@ -30,21 +33,42 @@ func WithCompressionLevel(v int) BuildOption {
 	}
 }
 
+// WithCompression sets the compression of the application layer. Layers that
+// are not gzip compressed are only supported by OCI manifests, so the image is
+// written as one.
+func WithCompression(v compression.Compression) BuildOption {
+	return func(bo *buildOptions) {
+		bo.compression = v
+	}
+}
+
 func WithLabels(v map[string]string) BuildOption {
 	return func(bo *buildOptions) {
 		bo.labels = v
 	}
 }
 
+// WithLayerDir sets the directory the layer is written to. It defaults to the
+// directory of the binary.
+func WithLayerDir(v string) BuildOption {
+	return func(bo *buildOptions) {
+		bo.layerDir = v
+	}
+}
+
 type buildOptions struct {
+	compression          compression.Compression
 	gzipCompressionLevel int
 	labels               map[string]string
+	layerDir             string
 }
 
 func defaultBuildOptions() *buildOptions {
 	return &buildOptions{
+		compression:          compression.GZip,
 		gzipCompressionLevel: gzip.DefaultCompression,
 		labels:               nil,
+		layerDir:             "",
 	}
 }
 
@@ -56,20 +80,163 @@ func WithLogger(v types.Logger) PushOption {
 	}
 }
 
+// WithProgress reports push progress to v. The tasks map attributes blob and
+// manifest digests to the task, e.g. a platform, that they belong to.
+func WithProgress(v types.Progress, tasks map[string]string) PushOption {
+	return func(po *pushOptions) {
+		po.progress = v
+		po.tasks = tasks
+	}
+}
+
 func WithTags(v []string) PushOption {
 	return func(po *pushOptions) {
 		po.tags = v
 	}
 }
 
+// WithJobs sets the maximum number of tags applied concurrently.
+func WithJobs(v int) PushOption {
+	return func(po *pushOptions) {
+		po.jobs = v
+	}
+}
+
+// WithImmutableTags prevents moving tags matching any of the path.Match
+// patterns (e.g. "*" or "v*") to a different digest. Each matching tag is
+// checked before anything is uploaded.
+func WithImmutableTags(v []string) PushOption {
+	return func(po *pushOptions) {
+		po.immutableTags = v
+	}
+}
+
+// WithSkipIfExists makes Push a no-op when the image's digest already exists
+// in the repository.
+func WithSkipIfExists(v bool) PushOption {
+	return func(po *pushOptions) {
+		po.skipIfExists = v
+	}
+}
+
+// WithCreateRepository creates the destination repository before pushing if
+// it does not exist. Only Amazon ECR repositories are created; other
+// registries create repositories on push.
+func WithCreateRepository(v bool) PushOption {
+	return func(po *pushOptions) {
+		po.createRepository = v
+	}
+}
+
+// WithRegistryOptions configures how the remote registry is accessed.
+func WithRegistryOptions(v ...RegistryOption) PushOption {
+	return func(po *pushOptions) {
+		for _, o := range v {
+			o(po.registry)
+		}
+	}
+}
+
 type pushOptions struct {
-	logger types.Logger
-	tags   []string
+	createRepository bool
+	immutableTags    []string
+	jobs             int
+	logger           types.Logger
+	progress         types.Progress
+	registry         *registryOptions
+	skipIfExists     bool
+	tags             []string
+	tasks            map[string]string
 }
 
 func defaultPushOptions() *pushOptions {
 	return &pushOptions{
-		logger: nil,
-		tags:   []string{DefaultTag},
+		createRepository: false,
+		immutableTags:    nil,
+		jobs:             4,
+		logger:           nil,
+		progress:         nil,
+		registry:         defaultRegistryOptions(),
+		skipIfExists:     false,
+		tags:             []string{DefaultTag},
+		tasks:            nil,
+	}
+}
+
+type RegistryOption func(*registryOptions)
+
+// WithRetries sets the number of times a failed request is retried.
+func WithRetries(v int) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.retries = v
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry. Each subsequent
+// retry waits three times longer than the last.
+func WithRetryBackoff(v time.Duration) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.retryBackoff = v
+	}
+}
+
+// WithTimeout sets how long to wait for a registry to respond to a request.
+func WithTimeout(v time.Duration) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.timeout = v
+	}
+}
+
+// WithInsecureRegistries sets the registries that are accessed over plain
+// HTTP.
+func WithInsecureRegistries(v []string) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.insecure = v
+	}
+}
+
+// WithCAFiles sets PEM encoded CA bundles that are trusted in addition to the
+// system roots.
+func WithCAFiles(v []string) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.caFiles = v
+	}
+}
+
+// WithMirrors maps registries to the mirrors that are tried first when
+// pulling from them.
+func WithMirrors(v map[string]string) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.mirrors = v
+	}
+}
+
+// WithCredentials sets explicit credentials by registry, which take precedence
+// over the Docker config file and other default keychains.
+func WithCredentials(v map[string]types.Credential) RegistryOption {
+	return func(ro *registryOptions) {
+		ro.credentials = v
+	}
+}
+
+type registryOptions struct {
+	caFiles      []string
+	credentials  map[string]types.Credential
+	insecure     []string
+	mirrors      map[string]string
+	retries      int
+	retryBackoff time.Duration
+	timeout      time.Duration
+}
+
+func defaultRegistryOptions() *registryOptions {
+	return &registryOptions{
+		caFiles:      nil,
+		credentials:  nil,
+		insecure:     nil,
+		mirrors:      nil,
+		retries:      2,
+		retryBackoff: time.Second,
+		timeout:      0,
 	}
 }
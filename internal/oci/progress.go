@@ -0,0 +1,92 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ryanfowler/gopack/internal/types"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// progressTransport observes registry requests to report the state of
+// individual blobs, which remote.WithProgress does not expose.
+type progressTransport struct {
+	inner    http.RoundTripper
+	progress types.Progress
+	// tasks maps blob and manifest digests to the task they belong to.
+	tasks map[string]string
+}
+
+func (t *progressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.inner.RoundTrip(req)
+	if err == nil {
+		t.observe(req, resp)
+	}
+	return resp, err
+}
+
+func (t *progressTransport) observe(req *http.Request, resp *http.Response) {
+	p := req.URL.Path
+	switch {
+	case req.Method == http.MethodHead && strings.Contains(p, "/blobs/") && !strings.Contains(p, "/uploads/"):
+		switch resp.StatusCode {
+		case http.StatusOK:
+			t.blob(path.Base(p), types.BlobSkipped)
+		case http.StatusNotFound:
+			t.blob(path.Base(p), types.BlobUploading)
+		}
+	case req.Method == http.MethodPost && strings.Contains(p, "/blobs/uploads/"):
+		if mount := req.URL.Query().Get("mount"); mount != "" && resp.StatusCode == http.StatusCreated {
+			t.blob(mount, types.BlobMounted)
+		}
+	case req.Method == http.MethodPut && strings.Contains(p, "/blobs/uploads/"):
+		if digest := req.URL.Query().Get("digest"); digest != "" && resp.StatusCode == http.StatusCreated {
+			t.blob(digest, types.BlobUploaded)
+		}
+	case req.Method == http.MethodPut && strings.Contains(p, "/manifests/"):
+		if task, ok := t.tasks[path.Base(p)]; ok && resp.StatusCode == http.StatusCreated {
+			t.progress.Phase(task, "pushed")
+		}
+	}
+}
+
+func (t *progressTransport) blob(digest string, state types.BlobState) {
+	if task, ok := t.tasks[digest]; ok {
+		t.progress.Blob(task, digest, state)
+	}
+}
+
+// forwardUpdates reports updates received on ch as transfer progress for the
+// task until ch is closed or ctx is done.
+func forwardUpdates(ctx context.Context, p types.Progress, wg *sync.WaitGroup, ch <-chan v1.Update, task string) {
+	defer wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-ch:
+			if !ok {
+				return
+			}
+			p.Transfer(task, update.Complete, update.Total)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// transport returns the base HTTP transport to use for registry requests.
+func (o *registryOptions) transport() (http.RoundTripper, error) {
+	if o.timeout <= 0 && len(o.caFiles) == 0 {
+		return remote.DefaultTransport, nil
+	}
+
+	t := remote.DefaultTransport.(*http.Transport).Clone()
+	if o.timeout > 0 {
+		t.ResponseHeaderTimeout = o.timeout
+		t.TLSHandshakeTimeout = o.timeout
+	}
+	if len(o.caFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, path := range o.caFiles {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(raw) {
+				return nil, fmt.Errorf("reading CA bundle %s: no certificates found", path)
+			}
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// remoteOptions returns the options shared by every remote operation, using
+// the provided transport.
+func (o *registryOptions) remoteOptions(ctx context.Context, t http.RoundTripper) []remote.Option {
+	return []remote.Option{
+		remote.WithContext(ctx),
+		remote.WithAuthFromKeychain(o.keychain(t)),
+		remote.WithTransport(t),
+		remote.WithRetryBackoff(remote.Backoff{
+			Duration: o.retryBackoff,
+			Factor:   3.0,
+			Jitter:   0.1,
+			Steps:    o.retries + 1,
+		}),
+	}
+}
+
+func (o *registryOptions) isInsecure(registry string) bool {
+	return slices.Contains(o.insecure, registry)
+}
+
+// resolveRegistry parses registry, marking it as insecure if it allows plain
+// HTTP.
+func (o *registryOptions) resolveRegistry(registry string) (name.Registry, error) {
+	var opts []name.Option
+	if o.isInsecure(registry) {
+		opts = append(opts, name.Insecure)
+	}
+	return name.NewRegistry(registry, opts...)
+}
+
+// resolveReference returns ref, marked as insecure if its registry allows
+// plain HTTP.
+func (o *registryOptions) resolveReference(ref name.Reference) (name.Reference, error) {
+	registry := ref.Context().RegistryStr()
+	if !o.isInsecure(registry) {
+		return ref, nil
+	}
+	return withRegistry(ref, registry, name.Insecure)
+}
+
+// resolveRepository returns repo, marked as insecure if its registry allows
+// plain HTTP.
+func (o *registryOptions) resolveRepository(repo name.Repository) (name.Repository, error) {
+	if !o.isInsecure(repo.RegistryStr()) {
+		return repo, nil
+	}
+	return name.NewRepository(repo.RegistryStr()+"/"+repo.RepositoryStr(), name.Insecure)
+}
+
+// mirrorReference returns ref rewritten to use the configured mirror for its
+// registry, if any.
+func (o *registryOptions) mirrorReference(ref name.Reference) (name.Reference, bool, error) {
+	mirror, ok := o.mirrorFor(ref.Context().Registry)
+	if !ok {
+		return nil, false, nil
+	}
+	var opts []name.Option
+	if o.isInsecure(mirror) {
+		opts = append(opts, name.Insecure)
+	}
+	out, err := withRegistry(ref, mirror, opts...)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid mirror %q: %w", mirror, err)
+	}
+	return out, true, nil
+}
+
+// mirrorFor returns the mirror configured for registry. Registry names are
+// normalized so that e.g. "docker.io" matches "index.docker.io".
+func (o *registryOptions) mirrorFor(registry name.Registry) (string, bool) {
+	for key, mirror := range o.mirrors {
+		reg, err := name.NewRegistry(key)
+		if err == nil && reg.RegistryStr() == registry.RegistryStr() {
+			return mirror, true
+		}
+	}
+	return "", false
+}
+
+func withRegistry(ref name.Reference, registry string, opts ...name.Option) (name.Reference, error) {
+	s := registry + "/" + ref.Context().RepositoryStr()
+	if _, ok := ref.(name.Digest); ok {
+		s += "@" + ref.Identifier()
+	} else {
+		s += ":" + ref.Identifier()
+	}
+	return name.ParseReference(s, opts...)
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestGetUsesMirror(t *testing.T) {
+	origin := newTestRegistry(t)
+	mirror := newTestRegistry(t)
+	img := writeRandomImage(t, mirror+"/library/base:latest")
+
+	ref, err := name.ParseReference(origin + "/library/base:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := Get(context.Background(), ref,
+		WithRetries(0),
+		WithMirrors(map[string]string{origin: mirror}))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want, _ := img.Digest(); desc.Digest != want {
+		t.Fatalf("Get() digest = %s, want %s", desc.Digest, want)
+	}
+}
+
+func TestGetFallsBackFromMirror(t *testing.T) {
+	origin := newTestRegistry(t)
+	mirror := newTestRegistry(t)
+	img := writeRandomImage(t, origin+"/library/base:latest")
+
+	ref, err := name.ParseReference(origin + "/library/base:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := Get(context.Background(), ref,
+		WithRetries(0),
+		WithMirrors(map[string]string{origin: mirror}))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if want, _ := img.Digest(); desc.Digest != want {
+		t.Fatalf("Get() digest = %s, want %s", desc.Digest, want)
+	}
+}
+
+func TestMirrorForNormalizesDockerHub(t *testing.T) {
+	opts := defaultRegistryOptions()
+	WithMirrors(map[string]string{"docker.io": "mirror.example.com"})(opts)
+
+	ref, err := name.ParseReference("ubuntu:22.04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, ok, err := opts.mirrorReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("mirrorReference() found no mirror for docker.io")
+	}
+	if got, want := out.String(), "mirror.example.com/library/ubuntu:22.04"; got != want {
+		t.Fatalf("mirrorReference() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveRepositoryInsecure(t *testing.T) {
+	opts := defaultRegistryOptions()
+	WithInsecureRegistries([]string{"registry.internal:5000"})(opts)
+
+	repo, err := name.NewRepository("registry.internal:5000/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err = opts.resolveRepository(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.Scheme(); got != "http" {
+		t.Fatalf("Scheme() = %q, want http", got)
+	}
+}
+
+func newTestRegistry(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host
+}
+
+func writeRandomImage(t *testing.T, ref string) v1.Image {
+	t.Helper()
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
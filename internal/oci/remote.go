@@ -18,32 +18,54 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strconv"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
 
-	"github.com/ryanfowler/gopack/internal/types"
-
-	"github.com/google/go-containerregistry/pkg/authn"
-	"github.com/google/go-containerregistry/pkg/authn/github"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
-	"github.com/google/go-containerregistry/pkg/v1/google"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
-var keychain = authn.NewMultiKeychain(
-	authn.DefaultKeychain,
-	google.Keychain,
-	github.Keychain,
-)
+// Get returns the descriptor for the provided image reference. If a mirror is
+// configured for the reference's registry, it is tried first.
+func Get(ctx context.Context, ref name.Reference, options ...RegistryOption) (*remote.Descriptor, error) {
+	opts := defaultRegistryOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	t, err := opts.transport()
+	if err != nil {
+		return nil, err
+	}
+	remoteOpts := opts.remoteOptions(ctx, t)
 
-// Get returns the descriptor for the provided image reference.
-func Get(ctx context.Context, ref name.Reference) (*remote.Descriptor, error) {
-	return remote.Get(ref,
-		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(keychain))
+	mirror, ok, err := opts.mirrorReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		desc, err := remote.Get(mirror, remoteOpts...)
+		if err == nil {
+			return desc, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	ref, err = opts.resolveReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := remote.Get(ref, remoteOpts...)
+	if err != nil {
+		return nil, wrapAuthError(err, ref.Context().RegistryStr(), "pull")
+	}
+	return desc, nil
 }
 
 // PushDaemon writes the provided image to the local Docker daemon.
@@ -84,7 +106,12 @@ func PushDaemon(ctx context.Context, imgName string, img v1.Image, options ...Pu
 }
 
 // Push writes img to the remote repo. The provided img must be either a
-// v1.Image or v1.ImageIndex.
+// v1.Image or v1.ImageIndex. The image is uploaded once by digest, then every
+// tag is applied concurrently with a single manifest PUT each. If only some
+// tags fail, the returned error is a *TagError. Immutable tags are checked
+// before anything is written, returning an *ImmutableTagError on conflict.
+// With WithSkipIfExists, Push is a no-op when the image's digest already
+// exists: nothing is uploaded and no tag is moved.
 func Push(ctx context.Context, repo name.Repository, img remote.Taggable, options ...PushOption) error {
 	opts := defaultPushOptions()
 	for _, o := range options {
@@ -94,25 +121,238 @@ func Push(ctx context.Context, repo name.Repository, img remote.Taggable, option
 	if len(opts.tags) == 0 {
 		return errors.New("push: no tags provided")
 	}
-
-	for i, raw := range opts.tags {
-		tag := repo.Tag(raw)
-		err := writeImage(ctx, tag, img, opts, i > 0)
+	repo, t, err := prepareRepository(ctx, repo, opts)
+	if err != nil {
+		return err
+	}
+	digest, err := digestOf(img)
+	if err != nil {
+		return err
+	}
+	if opts.skipIfExists || len(opts.immutableTags) > 0 {
+		exists, err := checkExisting(ctx, repo, digest, opts, t)
 		if err != nil {
-			return fmt.Errorf("push %q: %w", raw, err)
+			return err
+		}
+		if exists && opts.skipIfExists {
+			if opts.logger != nil {
+				opts.logger.Printf("Skipping %s: %s already exists\n", repo, digest)
+			}
+			return nil
 		}
 	}
 
+	if opts.progress != nil {
+		t = &progressTransport{
+			inner:    t,
+			progress: opts.progress,
+			tasks:    opts.tasks,
+		}
+	}
+
+	if err := writeImage(ctx, repo, img, repo.String(), opts, t); err != nil {
+		return fmt.Errorf("push: %w", wrapAuthError(err, repo.RegistryStr(), "push"))
+	}
+	return applyTags(ctx, repo, img, opts, t)
+}
+
+// Upload writes the blobs and manifest of img by digest, without applying any
+// tags, to the first of repos on each registry. A later PushAll of img, or of
+// an index referencing it, then only checks that they exist and mounts them
+// into the other repositories. Registries are uploaded to concurrently.
+func Upload(ctx context.Context, repos []name.Repository, img remote.Taggable, options ...PushOption) error {
+	opts := defaultPushOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	registries, groups := groupByRegistry(repos)
+	errs := make([]error, len(registries))
+	var wg sync.WaitGroup
+	for i, registry := range registries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo := groups[registry][0]
+			if err := upload(ctx, repo, img, opts); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", repo, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func upload(ctx context.Context, repo name.Repository, img remote.Taggable, opts *pushOptions) error {
+	repo, t, err := prepareRepository(ctx, repo, opts)
+	if err != nil {
+		return err
+	}
+	if opts.progress != nil {
+		t = &progressTransport{
+			inner:    t,
+			progress: opts.progress,
+			tasks:    opts.tasks,
+		}
+	}
+	// An image uploaded on its own reports progress for the task it belongs
+	// to, such as its platform, rather than for the repository.
+	digest, err := digestOf(img)
+	if err != nil {
+		return err
+	}
+	task := repo.String()
+	if v, ok := opts.tasks[digest.String()]; ok {
+		task = v
+	}
+	if err := writeImage(ctx, repo, img, task, opts, t); err != nil {
+		return fmt.Errorf("upload: %w", wrapAuthError(err, repo.RegistryStr(), "push"))
+	}
 	return nil
 }
 
-func writeImage(ctx context.Context, tag name.Tag, img remote.Taggable, opts *pushOptions, tagOnly bool) error {
-	remoteOpts := []remote.Option{
-		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(keychain),
+// prepareRepository resolves repo, creating it first if requested, and
+// returns the transport to use for it.
+func prepareRepository(ctx context.Context, repo name.Repository, opts *pushOptions) (name.Repository, http.RoundTripper, error) {
+	repo, err := opts.registry.resolveRepository(repo)
+	if err != nil {
+		return repo, nil, err
+	}
+	if opts.createRepository {
+		created, err := ensureECRRepository(ctx, repo)
+		if err != nil {
+			return repo, nil, err
+		}
+		if created && opts.logger != nil {
+			opts.logger.Printf("Created repository %s\n", repo)
+		}
+	}
+	t, err := opts.registry.transport()
+	return repo, t, err
+}
+
+// groupByRegistry groups repos by registry, returning the registries in the
+// order they first appear.
+func groupByRegistry(repos []name.Repository) ([]string, map[string][]name.Repository) {
+	var registries []string
+	groups := make(map[string][]name.Repository)
+	for _, repo := range repos {
+		registry := repo.RegistryStr()
+		if _, ok := groups[registry]; !ok {
+			registries = append(registries, registry)
+		}
+		groups[registry] = append(groups[registry], repo)
+	}
+	return registries, groups
+}
+
+// PushAll writes img to every repo. The image's blobs are uploaded once per
+// registry; additional repositories on the same registry mount them from the
+// first repository. Registries are pushed to concurrently. Immutable tags are
+// checked in every repository before any of them is written to.
+func PushAll(ctx context.Context, repos []name.Repository, img remote.Taggable, options ...PushOption) error {
+	if len(repos) == 0 {
+		return errors.New("push: no repositories provided")
+	}
+	if err := CheckImmutableTags(ctx, repos, img, options...); err != nil {
+		return err
+	}
+	// The tags were just checked, so each Push does not check them again.
+	options = append(slices.Clone(options), WithImmutableTags(nil))
+
+	registries, groups := groupByRegistry(repos)
+	errs := make([]error, len(registries))
+	var wg sync.WaitGroup
+	for i, registry := range registries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = pushRegistry(ctx, groups[registry], img, options)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// pushRegistry writes img to repos, which must all share the same registry.
+// Failing to apply some tags in one repository does not stop the image from
+// being pushed to the others.
+func pushRegistry(ctx context.Context, repos []name.Repository, img remote.Taggable, options []PushOption) error {
+	first := repos[0]
+	err := pushRepository(ctx, first, img, options)
+	var tagErr *TagError
+	if err != nil && !errors.As(err, &tagErr) {
+		return err
+	}
+	if len(repos) == 1 {
+		return err
+	}
+
+	mountable, mountErr := mountableFrom(ctx, first, img, options)
+	if mountErr != nil {
+		return errors.Join(err, fmt.Errorf("%s: %w", first, mountErr))
+	}
+	errs := []error{err}
+	for _, repo := range repos[1:] {
+		errs = append(errs, pushRepository(ctx, repo, mountable, options))
+	}
+	return errors.Join(errs...)
+}
+
+// pushRepository calls Push, prefixing errors that do not already name the
+// repository.
+func pushRepository(ctx context.Context, repo name.Repository, img remote.Taggable, options []PushOption) error {
+	err := Push(ctx, repo, img, options...)
+	var tagErr *TagError
+	if err == nil || errors.As(err, &tagErr) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", repo, err)
+}
+
+// mountableFrom returns img as read back from repo. Layers of a remote image
+// carry their source repository, which lets remote.Write mount them into
+// other repositories on the same registry instead of uploading them again.
+func mountableFrom(ctx context.Context, repo name.Repository, img remote.Taggable, options []PushOption) (remote.Taggable, error) {
+	opts := defaultPushOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	digest, err := digestOf(img)
+	if err != nil {
+		return nil, err
+	}
+	repo, err = opts.registry.resolveRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+	t, err := opts.registry.transport()
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := remote.Get(repo.Digest(digest.String()), opts.registry.remoteOptions(ctx, t)...)
+	if err != nil {
+		return nil, wrapAuthError(err, repo.RegistryStr(), "pull")
+	}
+	if desc.MediaType.IsIndex() {
+		return desc.ImageIndex()
+	}
+	return desc.Image()
+}
+
+// writeImage uploads the blobs and manifest of img to repo by digest,
+// reporting transfer progress for task.
+func writeImage(ctx context.Context, repo name.Repository, img remote.Taggable, task string, opts *pushOptions, t http.RoundTripper) error {
+	digest, err := digestOf(img)
+	if err != nil {
+		return err
 	}
+	ref := repo.Digest(digest.String())
+	remoteOpts := opts.registry.remoteOptions(ctx, t)
 
-	if opts.logger != nil {
+	if opts.progress != nil {
 		var wg sync.WaitGroup
 		wg.Add(1)
 		defer wg.Wait()
@@ -121,47 +361,106 @@ func writeImage(ctx context.Context, tag name.Tag, img remote.Taggable, opts *pu
 		defer cancel()
 
 		ch := make(chan v1.Update, 1)
-		go logProgress(ctx, opts.logger, &wg, ch, tag.TagStr())
+		go forwardUpdates(ctx, opts.progress, &wg, ch, task)
 		remoteOpts = append(remoteOpts, remote.WithProgress(ch))
 	}
 
-	if tagOnly {
-		return remote.Tag(tag, img, remoteOpts...)
+	switch img := img.(type) {
+	case v1.Image:
+		err = remote.Write(ref, img, remoteOpts...)
+	case v1.ImageIndex:
+		err = remote.WriteIndex(ref, img, remoteOpts...)
+	default:
+		err = errors.New("must be an image or image index")
 	}
-	if image, ok := img.(v1.Image); ok {
-		return remote.Write(tag, image, remoteOpts...)
+	if err == nil && opts.progress != nil {
+		opts.progress.Phase(task, "pushed")
 	}
-	if index, ok := img.(v1.ImageIndex); ok {
-		return remote.WriteIndex(tag, index, remoteOpts...)
-	}
-
-	return errors.New("must be an image or image index")
+	return err
 }
 
-func logProgress(ctx context.Context, l types.Logger, wg *sync.WaitGroup, ch <-chan v1.Update, tag string) {
-	defer func() {
-		l.Println()
-		wg.Done()
-	}()
-	l.Printf("Pushing tag %s", tag)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case update, ok := <-ch:
-			if !ok {
+// applyTags points every tag at the already uploaded img, running up to
+// opts.jobs manifest PUTs at a time. Every tag is attempted even if others
+// fail.
+func applyTags(ctx context.Context, repo name.Repository, img remote.Taggable, opts *pushOptions, t http.RoundTripper) error {
+	remoteOpts := opts.registry.remoteOptions(ctx, t)
+
+	jobs := max(opts.jobs, 1)
+	sem := make(chan struct{}, jobs)
+	errs := make([]error, len(opts.tags))
+	var wg sync.WaitGroup
+	for i, raw := range opts.tags {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			err := remote.Tag(repo.Tag(raw), img, remoteOpts...)
+			if err != nil {
+				errs[i] = wrapAuthError(err, repo.RegistryStr(), "push")
 				return
 			}
-			complete := bytesToMegaBytes(update.Complete)
-			total := bytesToMegaBytes(update.Total)
-			l.RePrintf("Pushing tag %s: %s/%s MB", tag, complete, total)
+			if opts.logger != nil {
+				opts.logger.Debugf("Tagged %s\n", repo.Tag(raw))
+			}
+		}()
+	}
+	wg.Wait()
+
+	tagErr := &TagError{Repository: repo.String()}
+	for i, err := range errs {
+		if err != nil {
+			tagErr.Failed = append(tagErr.Failed, TagFailure{Tag: opts.tags[i], Err: err})
+		} else {
+			tagErr.Applied = append(tagErr.Applied, opts.tags[i])
 		}
 	}
+	if len(tagErr.Failed) == 0 {
+		return nil
+	}
+	return tagErr
+}
+
+// TagError is returned when one or more tags could not be applied after the
+// image itself was pushed successfully.
+type TagError struct {
+	Repository string
+	// Applied contains the tags that were applied successfully.
+	Applied []string
+	// Failed contains the tags that could not be applied, in the order they
+	// were requested.
+	Failed []TagFailure
+}
+
+// TagFailure describes a single tag that could not be applied.
+type TagFailure struct {
+	Tag string
+	Err error
+}
+
+func (e *TagError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "push %s: %d of %d tags failed", e.Repository,
+		len(e.Failed), len(e.Failed)+len(e.Applied))
+	for _, f := range e.Failed {
+		fmt.Fprintf(&b, "; %s: %v", f.Tag, f.Err)
+	}
+	return b.String()
 }
 
-func bytesToMegaBytes(bytes int64) string {
-	mb := float64(bytes) / 1_000_000
-	out := strconv.FormatFloat(mb, 'f', 2, 64)
-	out = strings.TrimRight(out, "0")
-	return strings.TrimSuffix(out, ".")
+func (e *TagError) Unwrap() []error {
+	out := make([]error, 0, len(e.Failed))
+	for _, f := range e.Failed {
+		out = append(out, f.Err)
+	}
+	return out
+}
+
+func digestOf(img remote.Taggable) (v1.Hash, error) {
+	d, ok := img.(interface{ Digest() (v1.Hash, error) })
+	if !ok {
+		return v1.Hash{}, errors.New("must be an image or image index")
+	}
+	return d.Digest()
 }
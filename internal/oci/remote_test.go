@@ -0,0 +1,324 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestPushAll(t *testing.T) {
+	primary, primaryReqs := newRecordingRegistry(t, "dr-app")
+	dr, _ := newRecordingRegistry(t)
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := []name.Repository{
+		mustRepository(t, primary+"/app"),
+		mustRepository(t, primary+"/dr-app"),
+		mustRepository(t, dr+"/app"),
+	}
+	err = PushAll(context.Background(), repos, img,
+		WithTags([]string{"v1", "latest"}),
+		WithRegistryOptions(WithInsecureRegistries([]string{primary, dr})))
+	if err != nil {
+		t.Fatalf("PushAll() error = %v", err)
+	}
+
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range repos {
+		for _, tag := range []string{"v1", "latest"} {
+			desc, err := remote.Head(repo.Tag(tag))
+			if err != nil {
+				t.Fatalf("Head(%s:%s) error = %v", repo, tag, err)
+			}
+			if desc.Digest != want {
+				t.Fatalf("%s:%s digest = %s, want %s", repo, tag, desc.Digest, want)
+			}
+		}
+	}
+
+	// The test registry does not implement mounting, so only check that
+	// every blob of the second repository was requested as a mount.
+	var mounts int
+	for _, req := range primaryReqs() {
+		if strings.HasPrefix(req, "POST /v2/dr-app/blobs/uploads/?from=app&mount=") {
+			mounts++
+		}
+	}
+	if want := 3; mounts != want {
+		t.Fatalf("requested %d mounts into second repository, want %d", mounts, want)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	primary, primaryReqs := newRecordingRegistry(t)
+	dr, _ := newRecordingRegistry(t)
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := []name.Repository{
+		mustRepository(t, primary+"/app"),
+		mustRepository(t, primary+"/dr-app"),
+		mustRepository(t, dr+"/app"),
+	}
+	err = Upload(context.Background(), repos, img,
+		WithRegistryOptions(WithInsecureRegistries([]string{primary, dr})))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	for _, repo := range []name.Repository{repos[0], repos[2]} {
+		if _, err := remote.Head(repo.Digest(digest.String())); err != nil {
+			t.Fatalf("Head(%s@%s) error = %v", repo, digest, err)
+		}
+	}
+	for _, req := range primaryReqs() {
+		if strings.Contains(req, "/dr-app/") || strings.Contains(req, "/manifests/latest") {
+			t.Fatalf("Upload() requested %q, want only the first repository written by digest", req)
+		}
+	}
+}
+
+func TestPushReportsFailedTags(t *testing.T) {
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/manifests/locked") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := mustRepository(t, u.Host+"/app")
+	err = Push(context.Background(), repo, img,
+		WithTags([]string{"v1", "locked", "latest"}),
+		WithRegistryOptions(WithInsecureRegistries([]string{u.Host}), WithRetries(0)))
+
+	var tagErr *TagError
+	if !errors.As(err, &tagErr) {
+		t.Fatalf("Push() error = %v, want *TagError", err)
+	}
+	if len(tagErr.Failed) != 1 || tagErr.Failed[0].Tag != "locked" {
+		t.Fatalf("failed tags = %+v, want [locked]", tagErr.Failed)
+	}
+	if got, want := strings.Join(tagErr.Applied, ","), "v1,latest"; got != want {
+		t.Fatalf("applied tags = %s, want %s", got, want)
+	}
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Push() error = %v, want wrapped *AuthError", err)
+	}
+
+	for _, tag := range tagErr.Applied {
+		if _, err := remote.Head(repo.Tag(tag)); err != nil {
+			t.Fatalf("Head(%s) error = %v", tag, err)
+		}
+	}
+}
+
+func TestPushImmutableTags(t *testing.T) {
+	host, reqs := newRecordingRegistry(t)
+	repo := mustRepository(t, host+"/app")
+	options := func(tags ...string) []PushOption {
+		return []PushOption{
+			WithTags(tags),
+			WithImmutableTags([]string{"v*"}),
+			WithRegistryOptions(WithInsecureRegistries([]string{host})),
+		}
+	}
+
+	first, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(context.Background(), repo, first, options("v1", "latest")...); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	// Pushing the same digest again is allowed.
+	if err := Push(context.Background(), repo, first, options("v1", "latest")...); err != nil {
+		t.Fatalf("Push() same digest error = %v", err)
+	}
+
+	second, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Mutable tags can be moved.
+	if err := Push(context.Background(), repo, second, options("latest")...); err != nil {
+		t.Fatalf("Push() mutable tag error = %v", err)
+	}
+
+	before := len(reqs())
+	err = Push(context.Background(), repo, second, options("v1", "latest")...)
+	var immErr *ImmutableTagError
+	if !errors.As(err, &immErr) {
+		t.Fatalf("Push() error = %v, want *ImmutableTagError", err)
+	}
+	want, err := first.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if immErr.Existing != want {
+		t.Fatalf("Existing = %s, want %s", immErr.Existing, want)
+	}
+	for _, req := range reqs()[before:] {
+		if !strings.HasPrefix(req, "HEAD ") && !strings.HasPrefix(req, "GET /v2/") {
+			t.Fatalf("unexpected write after immutable tag conflict: %s", req)
+		}
+	}
+}
+
+func TestPushAllImmutableTagsBeforeWriting(t *testing.T) {
+	primary, primaryReqs := newRecordingRegistry(t)
+	dr, drReqs := newRecordingRegistry(t)
+	existing := writeRandomImage(t, dr+"/app:v1")
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := len(drReqs())
+	err = PushAll(context.Background(), []name.Repository{
+		mustRepository(t, primary+"/app"),
+		mustRepository(t, dr+"/app"),
+	}, img,
+		WithTags([]string{"v1", "latest"}),
+		WithImmutableTags([]string{"v*"}),
+		WithRegistryOptions(WithInsecureRegistries([]string{primary, dr})))
+	var immErr *ImmutableTagError
+	if !errors.As(err, &immErr) {
+		t.Fatalf("PushAll() error = %v, want *ImmutableTagError", err)
+	}
+	if want, _ := existing.Digest(); immErr.Existing != want {
+		t.Fatalf("Existing = %s, want %s", immErr.Existing, want)
+	}
+	reqs := append(primaryReqs(), drReqs()[before:]...)
+	for _, req := range reqs {
+		if !strings.HasPrefix(req, "HEAD ") && !strings.HasPrefix(req, "GET /v2/") {
+			t.Fatalf("unexpected write after immutable tag conflict: %s", req)
+		}
+	}
+}
+
+func TestPushSkipIfExists(t *testing.T) {
+	host, reqs := newRecordingRegistry(t)
+	repo := mustRepository(t, host+"/app")
+	img := writeRandomImage(t, host+"/app:v1")
+
+	before := len(reqs())
+	err := Push(context.Background(), repo, img,
+		WithTags([]string{"v2"}),
+		WithSkipIfExists(true),
+		WithRegistryOptions(WithInsecureRegistries([]string{host})))
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	for _, req := range reqs()[before:] {
+		if !strings.HasPrefix(req, "HEAD ") && !strings.HasPrefix(req, "GET /v2/") {
+			t.Fatalf("unexpected write with skip if exists: %s", req)
+		}
+	}
+	if _, err := remote.Head(repo.Tag("v2")); err == nil {
+		t.Fatal("tag v2 was applied with skip if exists")
+	}
+}
+
+func TestValidateTagPatterns(t *testing.T) {
+	if err := ValidateTagPatterns([]string{"*", "v[0-9]*"}); err != nil {
+		t.Fatalf("ValidateTagPatterns() error = %v", err)
+	}
+	if err := ValidateTagPatterns([]string{"v[0-9"}); err == nil {
+		t.Fatal("ValidateTagPatterns() error = nil, want bad pattern error")
+	}
+}
+
+// newRecordingRegistry returns a test registry that records every request.
+// The registry shares blobs across all repositories, so blob existence checks
+// in the isolated repositories always miss, as they would on a registry that
+// scopes blobs to a repository.
+func newRecordingRegistry(t *testing.T, isolated ...string) (string, func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var reqs []string
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		reqs = append(reqs, r.Method+" "+r.URL.RequestURI())
+		mu.Unlock()
+		for _, repo := range isolated {
+			if r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/v2/"+repo+"/blobs/") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Host, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), reqs...)
+	}
+}
+
+func mustRepository(t *testing.T, raw string) name.Repository {
+	t.Helper()
+
+	repo, err := name.NewRepository(raw, name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "context"
+
+// Builder produces the executable that is packaged into the image for a
+// platform. Implementations must be safe for concurrent use, as platforms are
+// built concurrently.
+type Builder interface {
+	// Build writes the executable for platform to outPath.
+	Build(ctx context.Context, outPath string, platform Platform) error
+}
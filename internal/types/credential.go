@@ -0,0 +1,26 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// Credential configures how to authenticate to a single registry. If Helper
+// is set, the docker-credential-<Helper> program is used. Otherwise, if
+// TokenFile is set, its contents are used as a bearer token. Otherwise,
+// Username and Password are used.
+type Credential struct {
+	Username  string
+	Password  string
+	TokenFile string
+	Helper    string
+}
@@ -14,9 +14,24 @@
 
 package types
 
-// Logger represents the interface to log to stderr.
+// Level is the severity of a log message. Its values match those of
+// log/slog so that levels can be converted directly.
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+// Logger represents the interface to log to stderr. Printf and Println log at
+// the info level.
 type Logger interface {
 	Printf(format string, a ...any)
 	Println(a ...any)
-	RePrintf(format string, a ...any)
+
+	Debugf(format string, a ...any)
+	Warnf(format string, a ...any)
+	Errorf(format string, a ...any)
 }
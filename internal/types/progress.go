@@ -0,0 +1,52 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+// Progress receives updates about named tasks, such as the build of a single
+// platform or the push of a single tag. Implementations must be safe for
+// concurrent use.
+type Progress interface {
+	// Phase reports that the task has entered a new phase, e.g. "compiling".
+	Phase(task, phase string)
+	// Blob reports a state change of a blob belonging to the task.
+	Blob(task, digest string, state BlobState)
+	// Transfer reports the number of bytes uploaded for the task.
+	Transfer(task string, complete, total int64)
+}
+
+// BlobState is the upload state of a single blob.
+type BlobState int
+
+const (
+	BlobUploading BlobState = iota
+	BlobUploaded
+	BlobSkipped
+	BlobMounted
+)
+
+func (s BlobState) String() string {
+	switch s {
+	case BlobUploading:
+		return "uploading"
+	case BlobUploaded:
+		return "uploaded"
+	case BlobSkipped:
+		return "skipped existing"
+	case BlobMounted:
+		return "mounted"
+	default:
+		return "unknown"
+	}
+}
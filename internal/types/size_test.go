@@ -0,0 +1,66 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"1024", 1024},
+		{"512B", 512},
+		{"50MB", 50_000_000},
+		{"50mb", 50_000_000},
+		{"20M", 20_000_000},
+		{"1.5GiB", 1536 << 20},
+		{"64 KiB", 64 << 10},
+		{"2kB", 2000},
+	}
+	for _, test := range tests {
+		got, err := ParseSize(test.input)
+		if err != nil {
+			t.Fatalf("ParseSize(%q) error = %v", test.input, err)
+		}
+		if got != test.want {
+			t.Fatalf("ParseSize(%q) = %d, want %d", test.input, got, test.want)
+		}
+	}
+
+	for _, input := range []string{"", "MB", "-1MB", "ten", "5XB", "1e30TB", "nan", "NaNMB", "inf", "+InfGB", "-inf"} {
+		if _, err := ParseSize(input); err == nil {
+			t.Fatalf("ParseSize(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		input int64
+		want  string
+	}{
+		{999, "999 B"},
+		{1500, "1.5 kB"},
+		{52_400_000, "52.4 MB"},
+	}
+	for _, test := range tests {
+		if got := FormatSize(test.input); got != test.want {
+			t.Fatalf("FormatSize(%d) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gopack builds Go programs into container images and publishes them,
+// exactly as the gopack command does.
+//
+// Run compiles the main package for every requested platform, layers each
+// binary on top of a base image, and pushes, loads, or writes the result. It
+// returns a Result describing every image, digest and reference produced:
+//
+//	res, err := gopack.Run(ctx,
+//		gopack.WithMainPath("./cmd/app"),
+//		gopack.WithRepository("ghcr.io/owner/app"),
+//		gopack.WithPlatforms([]string{"linux/amd64", "linux/arm64"}),
+//		gopack.WithTags([]string{"v1.2.3"}),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(res.Digest)
+//
+// # Compatibility
+//
+// This package follows semantic versioning. Within a major version, exported
+// identifiers will not be removed or changed incompatibly, options keep their
+// meaning and defaults, and the JSON encoding of Result and Inspection only
+// gains fields. New options, Result fields and error types may be added in
+// minor releases. Other packages in this module are internal and carry no
+// compatibility guarantees.
+package gopack
@@ -0,0 +1,207 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+	"github.com/ryanfowler/gopack/internal/oci"
+	"github.com/ryanfowler/gopack/internal/types"
+)
+
+// Environment variables holding credentials for the destination registry.
+const (
+	EnvRegistryUsername = gopack.EnvRegistryUsername
+	EnvRegistryPassword = gopack.EnvRegistryPassword
+)
+
+// Dry run modes, see WithDryRun.
+const (
+	// DryRunPlan resolves the base, validates the platforms and computes the
+	// destinations without compiling or writing anything.
+	DryRunPlan = gopack.DryRunPlan
+	// DryRunBuild additionally compiles and layers every image so that the
+	// planned digests are known, but still writes nothing.
+	DryRunBuild = gopack.DryRunBuild
+)
+
+// Supported compilers, see WithCompiler.
+const (
+	CompilerGo     = gopack.CompilerGo
+	CompilerTinyGo = gopack.CompilerTinyGo
+)
+
+// TagStrategySemver derives version tags from a semver git tag on HEAD, see
+// WithTagStrategy.
+const TagStrategySemver = gopack.TagStrategySemver
+
+// Size budget modes and kinds, see WithSizeBudgetMode.
+const (
+	SizeBudgetFail   = gopack.SizeBudgetFail
+	SizeBudgetWarn   = gopack.SizeBudgetWarn
+	SizeBudgetImage  = gopack.SizeBudgetImage
+	SizeBudgetBinary = gopack.SizeBudgetBinary
+)
+
+// Log levels, see NewTextLogger and NewJSONLogger.
+const (
+	LevelDebug = types.LevelDebug
+	LevelInfo  = types.LevelInfo
+	LevelWarn  = types.LevelWarn
+	LevelError = types.LevelError
+)
+
+// ErrNoMatchingImage is returned when the base image has no manifest for a
+// requested platform.
+var ErrNoMatchingImage = gopack.ErrNoMatchingImage
+
+// RunOption configures Run, RunPackages, Push and Inspect.
+type RunOption = gopack.RunOption
+
+type (
+	// Result describes everything produced by a call to Run or Push.
+	Result = gopack.Result
+	// PackagesResult describes everything produced by a call to
+	// RunPackages.
+	PackagesResult = gopack.PackagesResult
+	// BaseResult describes the resolved base image.
+	BaseResult = gopack.BaseResult
+	// ImageResult describes a single per-platform image.
+	ImageResult = gopack.ImageResult
+	// ImageSize is a breakdown of the compressed layer size of an image.
+	ImageSize = gopack.ImageSize
+	// LayerResult describes a single compressed image layer.
+	LayerResult = gopack.LayerResult
+	// Durations records how long each phase of Run took.
+	Durations = gopack.Durations
+	// Duration is a time.Duration that is encoded in JSON as a string.
+	Duration = gopack.Duration
+	// SizeViolation describes an image that exceeds a size budget.
+	SizeViolation = gopack.SizeViolation
+)
+
+type (
+	// Inspection describes an image or index read by Inspect.
+	Inspection = gopack.Inspection
+	// ImageInspection describes a single image manifest and its config.
+	ImageInspection = gopack.ImageInspection
+	// ConfigInspection contains the runtime configuration of an image.
+	ConfigInspection = gopack.ConfigInspection
+	// HistoryInspection is a single entry of an image's history.
+	HistoryInspection = gopack.HistoryInspection
+)
+
+type (
+	// SizeBudgetError is returned when an image exceeds a size budget.
+	SizeBudgetError = gopack.SizeBudgetError
+	// AuthError is returned when a registry rejects the credentials used.
+	AuthError = oci.AuthError
+	// TagError is returned when an image was pushed but some of its tags
+	// could not be applied.
+	TagError = oci.TagError
+	// TagFailure is a single tag that could not be applied.
+	TagFailure = oci.TagFailure
+	// ImmutableTagError is returned when pushing would move an immutable tag
+	// to a different digest.
+	ImmutableTagError = oci.ImmutableTagError
+)
+
+// PlatformOptions overrides options for a single platform, see
+// WithPlatformOptions. Empty fields keep the shared values.
+type PlatformOptions = gopack.PlatformOptions
+
+// Credential configures how to authenticate to a single registry, see
+// WithCredentials.
+type Credential = types.Credential
+
+// Builder produces the executable that is packaged into the image for a
+// platform, see WithBuilder. Implementations must be safe for concurrent use.
+type Builder = types.Builder
+
+// Platform is an image platform, such as linux/arm64 or linux/arm/v7. Use
+// its OS, Arch and Variant methods to inspect it.
+type Platform = types.Platform
+
+// Logger receives the log messages of a run, see WithLogger.
+type Logger = types.Logger
+
+// Level is the severity of a log message. Its values match those of
+// log/slog.
+type Level = types.Level
+
+// Run builds the Go binary for every requested platform, packages each as an
+// image on top of the base, and pushes, loads, or writes the result. If a size
+// budget fails, the result of the build, including its sizes, is returned with
+// the *SizeBudgetError, and nothing is pushed, loaded, or written.
+func Run(ctx context.Context, options ...RunOption) (*Result, error) {
+	return gopack.Run(ctx, options...)
+}
+
+// RunPackages calls Run for every main package matching the pattern, such as
+// "./cmd/...". With WithSince, packages not affected by the changes since a
+// git ref are skipped. When the pattern contains "...", each repository is a
+// prefix that the binary name of every package is appended to. If a package
+// fails, the results of the packages already built, which may have been
+// published, are returned with the error, followed by the failed package's
+// result if Run returned one.
+func RunPackages(ctx context.Context, pattern string, options ...RunOption) (*PackagesResult, error) {
+	return gopack.RunPackages(ctx, pattern, options...)
+}
+
+// Push pushes the OCI archive at archive ("oci:<path>"), as written by Run
+// with WithOutput, to the configured repositories without rebuilding.
+// Manifests are pushed unchanged, so the pushed digest matches the one Run
+// reported, even for a single image, which is pushed within its index.
+func Push(ctx context.Context, archive string, options ...RunOption) (*Result, error) {
+	return gopack.Push(ctx, archive, options...)
+}
+
+// Inspect reads the image or index at target, which is either a remote
+// reference or an OCI archive as "oci:<path>". Only the registry and logger
+// options apply.
+func Inspect(ctx context.Context, target string, options ...RunOption) (*Inspection, error) {
+	return gopack.Inspect(ctx, target, options...)
+}
+
+// StdErrLogger returns the logger used by default, which writes text to
+// stderr at the info level.
+func StdErrLogger() Logger {
+	return gopack.StdErrLogger()
+}
+
+// NewTextLogger returns a logger writing human-readable messages at or above
+// level to w.
+func NewTextLogger(w io.Writer, level Level) Logger {
+	return gopack.NewTextLogger(w, level)
+}
+
+// NewJSONLogger returns a logger writing JSON messages at or above level to
+// w.
+func NewJSONLogger(w io.Writer, level Level) Logger {
+	return gopack.NewJSONLogger(w, level)
+}
+
+// SlogLogger returns a logger writing to h.
+func SlogLogger(h slog.Handler) Logger {
+	return gopack.SlogLogger(h)
+}
+
+// NopLogger returns a logger that discards every message.
+func NopLogger() Logger {
+	return gopack.NopLogger()
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryanfowler/gopack/pkg/gopack"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestOptionsMirrorInternalPackage(t *testing.T) {
+	internal := optionNames(t, "../../internal/gopack")
+	public := optionNames(t, ".")
+	for _, name := range internal {
+		if !slices.Contains(public, name) {
+			t.Errorf("option %s is not exposed by pkg/gopack", name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := u.Host
+
+	base, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := base.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config = config.DeepCopy()
+	config.OS, config.Architecture = "linux", "amd64"
+	if base, err = mutate.ConfigFile(base, config); err != nil {
+		t.Fatal(err)
+	}
+	baseRef, err := name.ParseReference(host + "/base:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(baseRef, base); err != nil {
+		t.Fatal(err)
+	}
+	baseDigest, err := base.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := gopack.Run(context.Background(),
+		gopack.WithLogger(gopack.NopLogger()),
+		gopack.WithBase(host+"/base:latest"),
+		gopack.WithMainPath(writeMain(t)),
+		gopack.WithRepository(host+"/app"),
+		gopack.WithTags([]string{"v1"}),
+	)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if res.Reference != host+"/app:v1" || res.Base.Digest != baseDigest.String() {
+		t.Fatalf("Result = %+v, want reference %s/app:v1 on base %s", res, host, baseDigest)
+	}
+	if len(res.Images) != 1 || res.Images[0].Digest != res.Digest {
+		t.Fatalf("Result.Images = %+v, want single image %s", res.Images, res.Digest)
+	}
+}
+
+// optionNames returns the exported With* functions declared in dir.
+func optionNames(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "With") {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		t.Fatalf("no options found in %s", dir)
+	}
+	return names
+}
+
+func writeMain(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
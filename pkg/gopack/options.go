@@ -0,0 +1,305 @@
+// Copyright 2026 Ryan Fowler
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gopack
+
+import (
+	"time"
+
+	"github.com/ryanfowler/gopack/internal/gopack"
+)
+
+// WithConcurrency sets the number of platforms built concurrently. The
+// default is GOMAXPROCS.
+func WithConcurrency(v int) RunOption {
+	return gopack.WithConcurrency(v)
+}
+
+// WithPushJobs sets the number of images uploaded concurrently. When pushing
+// to registries, each platform's image is uploaded as soon as it is built, so
+// uploads overlap with the remaining builds. The default is 4.
+func WithPushJobs(v int) RunOption {
+	return gopack.WithPushJobs(v)
+}
+
+// WithLogger sets the logger. The default is StdErrLogger.
+func WithLogger(v Logger) RunOption {
+	return gopack.WithLogger(v)
+}
+
+// WithCompiler sets the compiler used to build the main package: CompilerGo
+// (the default) or CompilerTinyGo. TinyGo only supports the -s, -w and -X
+// ldflags, and does not support cgo or -mod.
+func WithCompiler(v string) RunOption {
+	return gopack.WithCompiler(v)
+}
+
+// WithBuildTags sets the build tags passed to the compiler.
+func WithBuildTags(v []string) RunOption {
+	return gopack.WithBuildTags(v)
+}
+
+// WithCGOEnabled enables cgo during compilation. It is disabled by default.
+func WithCGOEnabled(v bool) RunOption {
+	return gopack.WithCGOEnabled(v)
+}
+
+// WithLDFlags sets the -ldflags passed to go build. The default is "-s -w".
+func WithLDFlags(v string) RunOption {
+	return gopack.WithLDFlags(v)
+}
+
+// WithEnv sets additional environment variables, as KEY=VALUE, for the
+// compiler. GOOS, GOARCH, the architecture variables (e.g. GOARM) and
+// CGO_ENABLED are set from the platform and options, and cannot be set.
+func WithEnv(v []string) RunOption {
+	return gopack.WithEnv(v)
+}
+
+// WithMainPath sets the main package to build. The default is ".".
+func WithMainPath(v string) RunOption {
+	return gopack.WithMainPath(v)
+}
+
+// WithModFlag sets the -mod flag passed to go build.
+func WithModFlag(v string) RunOption {
+	return gopack.WithModFlag(v)
+}
+
+// WithWorkdir sets the directory that go and git commands run in and that a
+// local main path is relative to. It defaults to the current directory. A
+// local main package is built from the root of its own module, so a package
+// in a nested module of a monorepo can be built from the repository root.
+func WithWorkdir(v string) RunOption {
+	return gopack.WithWorkdir(v)
+}
+
+// WithGoWork sets GOWORK for the go commands run: the path of a go.work file,
+// relative to the workdir if not absolute, or "off" to disable workspaces. By
+// default, GOWORK is inherited from the environment.
+func WithGoWork(v string) RunOption {
+	return gopack.WithGoWork(v)
+}
+
+// WithTrimpath sets whether -trimpath is passed to go build. It is enabled by
+// default.
+func WithTrimpath(v bool) RunOption {
+	return gopack.WithTrimpath(v)
+}
+
+// WithBuilder sets the builder that produces each platform's binary instead
+// of compiling the main package with go build.
+func WithBuilder(v Builder) RunOption {
+	return gopack.WithBuilder(v)
+}
+
+// WithPrebuilt packages binaries built elsewhere instead of compiling them.
+// The keys are platforms (e.g. "linux/arm64") and the values are paths to
+// Linux executables for them, which are validated before packaging. Unless
+// set with WithPlatforms, the platforms are those of the binaries.
+func WithPrebuilt(v map[string]string) RunOption {
+	return gopack.WithPrebuilt(v)
+}
+
+// WithPlatformOptions overrides the base image, ldflags, build tags or
+// environment of individual platforms, keyed by platform (e.g.
+// "linux/arm/v6"). Every platform must be one of those built, and only the
+// base can be overridden when packaging prebuilt binaries or using a custom
+// builder.
+func WithPlatformOptions(v map[string]PlatformOptions) RunOption {
+	return gopack.WithPlatformOptions(v)
+}
+
+// WithBase sets the base image. The default is
+// "gcr.io/distroless/static:nonroot".
+func WithBase(v string) RunOption {
+	return gopack.WithBase(v)
+}
+
+// WithCompressionLevel sets the compression level of the application layer.
+func WithCompressionLevel(v int) RunOption {
+	return gopack.WithCompressionLevel(v)
+}
+
+// WithLayerCompression sets the compression of the application layer: "gzip"
+// (the default), "zstd" or "none". Images with zstd or uncompressed layers
+// are written as OCI manifests.
+func WithLayerCompression(v string) RunOption {
+	return gopack.WithLayerCompression(v)
+}
+
+// WithUPX packs each binary with UPX before layering it, if a upx executable
+// is found in PATH.
+func WithUPX(v bool) RunOption {
+	return gopack.WithUPX(v)
+}
+
+// WithDaemon loads the image into a local daemon instead of pushing it. The
+// only supported daemon is "docker".
+func WithDaemon(v string) RunOption {
+	return gopack.WithDaemon(v)
+}
+
+// WithLoad loads the image into the local Docker daemon instead of pushing
+// it.
+func WithLoad(v bool) RunOption {
+	return gopack.WithLoad(v)
+}
+
+// WithOutput writes the image to an OCI archive ("oci:<path>") instead of
+// pushing it.
+func WithOutput(v string) RunOption {
+	return gopack.WithOutput(v)
+}
+
+// WithLabels sets labels on every image.
+func WithLabels(v map[string]string) RunOption {
+	return gopack.WithLabels(v)
+}
+
+// WithPlatforms sets the platforms to build for (e.g. "linux/arm64" or
+// "linux/arm/v7"). The default is "linux/amd64".
+func WithPlatforms(v []string) RunOption {
+	return gopack.WithPlatforms(v)
+}
+
+// WithPlatformTags sets whether each platform's image is also tagged as
+// <tag>-<os>-<arch>[-<variant>] (e.g. v1.2.0-linux-arm-v7) for every tag,
+// alongside the multi-platform index, for clients that cannot resolve an
+// image index. It requires pushing to a registry.
+func WithPlatformTags(v bool) RunOption {
+	return gopack.WithPlatformTags(v)
+}
+
+// WithRepository sets the single repository to push or load the image to.
+// The default is the name of the main package's directory.
+func WithRepository(v string) RunOption {
+	return gopack.WithRepository(v)
+}
+
+// WithRepositories sets every repository to push or load the image to.
+func WithRepositories(v []string) RunOption {
+	return gopack.WithRepositories(v)
+}
+
+// WithTags sets the tags to apply. The default is "latest" unless tags are
+// derived with WithTagStrategy or WithTagTemplates.
+func WithTags(v []string) RunOption {
+	return gopack.WithTags(v)
+}
+
+// WithSince only builds the main packages matched by RunPackages that are
+// affected by the changes in the working tree since the merge base of the git
+// ref and HEAD: changes to the files of the package or its dependencies, to
+// embedded files, or to the go.mod, go.sum and go.work files involved. Commits
+// made on the ref after the merge base are ignored. It is ignored by Run.
+func WithSince(v string) RunOption {
+	return gopack.WithSince(v)
+}
+
+// WithTagStrategy derives additional tags from the git checkout. The only
+// supported strategy is TagStrategySemver.
+func WithTagStrategy(v string) RunOption {
+	return gopack.WithTagStrategy(v)
+}
+
+// WithTagTemplates adds tags rendered from Go templates. Templates can use
+// {{.Commit}}, {{.ShortCommit}}, {{.Branch}} and {{.Date}}.
+func WithTagTemplates(v []string) RunOption {
+	return gopack.WithTagTemplates(v)
+}
+
+// WithDryRun resolves and plans the run without writing to any registry,
+// daemon or archive. The mode is DryRunPlan or DryRunBuild.
+func WithDryRun(v string) RunOption {
+	return gopack.WithDryRun(v)
+}
+
+// WithImmutableTags refuses to move tags matching any of the path.Match
+// patterns (e.g. "*" or "v*") to a different digest when pushing to a
+// registry.
+func WithImmutableTags(v []string) RunOption {
+	return gopack.WithImmutableTags(v)
+}
+
+// WithSkipIfExists skips pushing to a repository that already contains the
+// image's digest, leaving its tags, including platform tags, unchanged.
+func WithSkipIfExists(v bool) RunOption {
+	return gopack.WithSkipIfExists(v)
+}
+
+// WithCreateRepository creates missing Amazon ECR repositories before
+// pushing.
+func WithCreateRepository(v bool) RunOption {
+	return gopack.WithCreateRepository(v)
+}
+
+// WithMaxImageSize sets a budget, in bytes, for the total compressed size of
+// each image. Zero disables the check.
+func WithMaxImageSize(v int64) RunOption {
+	return gopack.WithMaxImageSize(v)
+}
+
+// WithMaxBinarySize sets a budget, in bytes, for the compressed size of the
+// layers added on top of the base image. Zero disables the check.
+func WithMaxBinarySize(v int64) RunOption {
+	return gopack.WithMaxBinarySize(v)
+}
+
+// WithSizeBudgetMode sets whether exceeding a size budget fails the run
+// (SizeBudgetFail, the default) or only logs a warning (SizeBudgetWarn).
+func WithSizeBudgetMode(v string) RunOption {
+	return gopack.WithSizeBudgetMode(v)
+}
+
+// WithRetries sets how many times failed registry requests are retried. The
+// default is 2.
+func WithRetries(v int) RunOption {
+	return gopack.WithRetries(v)
+}
+
+// WithRetryBackoff sets the delay before the first registry retry. The
+// default is one second.
+func WithRetryBackoff(v time.Duration) RunOption {
+	return gopack.WithRetryBackoff(v)
+}
+
+// WithRegistryTimeout sets how long to wait for a registry response. There
+// is no timeout by default.
+func WithRegistryTimeout(v time.Duration) RunOption {
+	return gopack.WithRegistryTimeout(v)
+}
+
+// WithInsecureRegistries sets the registries to access over plain HTTP.
+func WithInsecureRegistries(v []string) RunOption {
+	return gopack.WithInsecureRegistries(v)
+}
+
+// WithCAFiles adds PEM CA bundles to trust for registries.
+func WithCAFiles(v []string) RunOption {
+	return gopack.WithCAFiles(v)
+}
+
+// WithRegistryMirrors sets mirrors, by registry, to pull the base image from
+// before falling back to the original registry.
+func WithRegistryMirrors(v map[string]string) RunOption {
+	return gopack.WithRegistryMirrors(v)
+}
+
+// WithCredentials sets explicit credentials by registry. Credentials from the
+// GOPACK_REGISTRY_USERNAME and GOPACK_REGISTRY_PASSWORD environment variables
+// are used for the destination registry if it has none.
+func WithCredentials(v map[string]Credential) RunOption {
+	return gopack.WithCredentials(v)
+}